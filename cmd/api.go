@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hibiken/asynq"
+	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// NewAPICmd creates a new api command
+func NewAPICmd(manager crawler.CrawlManagerInterface) *cobra.Command {
+	var port int
+
+	apiCmd := &cobra.Command{
+		Use:   "api",
+		Short: "Start the HTTP API server",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runAPICmd(manager, port)
+		},
+	}
+
+	apiCmd.Flags().IntVarP(&port, "port", "p", 3000, "Port to serve the API on")
+
+	return apiCmd
+}
+
+func runAPICmd(manager crawler.CrawlManagerInterface, port int) error {
+	// Check if manager is nil
+	if manager == nil {
+		fmt.Println("Error: manager is nil")
+		return errors.New("manager is nil")
+	}
+
+	redisConnOpt, err := asynqRedisOpt(manager)
+	if err != nil {
+		return err
+	}
+
+	client := asynq.NewClient(redisConnOpt)
+	defer func(client *asynq.Client) {
+		err := client.Close()
+		if err != nil {
+			fmt.Printf("failed to close asynq client: %v", err)
+		}
+	}(client)
+
+	inspector := asynq.NewInspector(redisConnOpt)
+	defer func(inspector *asynq.Inspector) {
+		err := inspector.Close()
+		if err != nil {
+			fmt.Printf("failed to close asynq inspector: %v", err)
+		}
+	}(inspector)
+
+	server := api.NewServer(client, inspector, manager)
+
+	manager.GetLogger().Info(fmt.Sprintf("Serving API on http://localhost:%d%s", port, api.BasePath))
+	return http.ListenAndServe(fmt.Sprintf(":%d", port), server.Handler())
+}
@@ -14,7 +14,7 @@ import (
 func NewClearlinksCmd(manager crawler.CrawlManagerInterface) *cobra.Command {
 	clearlinksCmd := &cobra.Command{
 		Use:   "clearlinks",
-		Short: "Clear the Redis set for a given siteid",
+		Short: "Clear the saved links for a given siteid",
 		RunE: func(_ *cobra.Command, _ []string) error {
 			return ClearlinksMain(manager)
 		},
@@ -37,17 +37,17 @@ func ClearlinksMain(manager crawler.CrawlManagerInterface) error {
 
 	dbManager := manager.GetDBManager()
 
-	err := dbManager.ClearRedisSet(context.Background(), siteid)
+	err := dbManager.ClearResults(context.Background(), siteid)
 	if err != nil {
-		return fmt.Errorf("failed to clear Redis set: %v", err)
+		return fmt.Errorf("failed to clear links: %v", err)
 	}
 
 	debug := viper.GetBool("debug")
 	if debug {
-		manager.GetLogger().Debug("Debugging enabled. Clearing Redis set...")
+		manager.GetLogger().Debug("Debugging enabled. Clearing links...")
 	}
 
-	manager.GetLogger().Info("Redis set cleared successfully")
+	manager.GetLogger().Info("Links cleared successfully")
 
 	return nil
 }
@@ -1,10 +1,19 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/jonesrussell/loggo"
 	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/internal/matcher"
+	"github.com/jonesrussell/page-prowler/internal/termmatcher"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -14,8 +23,12 @@ func NewCrawlCmd(manager crawler.CrawlManagerInterface) *cobra.Command {
 	crawlCmd := &cobra.Command{
 		Use:   "crawl",
 		Short: "Crawl!",
-		RunE: func(_ *cobra.Command, _ []string) error {
-			return runCrawlCmd(manager)
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// Stop crawling cleanly on Ctrl+C
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return runCrawlCmd(ctx, manager)
 		},
 	}
 
@@ -24,25 +37,126 @@ func NewCrawlCmd(manager crawler.CrawlManagerInterface) *cobra.Command {
 		fmt.Println("Error binding flag", err)
 	}
 
-	crawlCmd.Flags().StringP("url", "u", "", "URL to crawl")
+	crawlCmd.Flags().StringSliceP("url", "u", nil, "URL to crawl, repeat or separate with commas to crawl several")
 	if err := viper.BindPFlag("url", crawlCmd.Flags().Lookup("url")); err != nil {
 		fmt.Println("Error binding flag", err)
 	}
 
+	crawlCmd.Flags().String("urlfile", "", "File of URLs to crawl, one per line")
+	if err := viper.BindPFlag("urlfile", crawlCmd.Flags().Lookup("urlfile")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().StringSlice("alloweddomains", nil, "Domains to crawl besides those of the URLs, e.g. \"cp24.com,*.bell.ca\" (\"*.\" for all subdomains)")
+	if err := viper.BindPFlag("alloweddomains", crawlCmd.Flags().Lookup("alloweddomains")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().StringArray("include", nil, "Only follow and report links containing this, \"*\" matching anything, or matching a regexp prefixed with \"re:\" (repeatable)")
+	if err := viper.BindPFlag("include", crawlCmd.Flags().Lookup("include")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().StringArray("exclude", nil, "Never follow or report links containing this, \"*\" matching anything, or matching a regexp prefixed with \"re:\" (repeatable)")
+	if err := viper.BindPFlag("exclude", crawlCmd.Flags().Lookup("exclude")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
 	crawlCmd.Flags().IntP("maxdepth", "m", 1, "Max depth for crawling")
 	if err := viper.BindPFlag("maxdepth", crawlCmd.Flags().Lookup("maxdepth")); err != nil {
 		fmt.Println("Error binding flag", err)
 	}
 
-	crawlCmd.Flags().StringP("searchterms", "t", "", "Search terms for crawling")
+	crawlCmd.Flags().StringP("searchterms", "t", "", "Comma separated search terms; quote a phrase to match it exactly")
 	if err := viper.BindPFlag("searchterms", crawlCmd.Flags().Lookup("searchterms")); err != nil {
 		fmt.Println("Error binding flag", err)
 	}
 
+	crawlCmd.Flags().Int("maxconcurrentrequests", crawler.DefaultParallelism, "Max concurrent requests per domain")
+	if err := viper.BindPFlag("maxconcurrentrequests", crawlCmd.Flags().Lookup("maxconcurrentrequests")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().Duration("delaybetweenrequests", crawler.DefaultDelay, "Delay between requests to the same domain")
+	if err := viper.BindPFlag("delaybetweenrequests", crawlCmd.Flags().Lookup("delaybetweenrequests")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().Duration("randomdelay", 0, "Max random delay added to the delay between requests")
+	if err := viper.BindPFlag("randomdelay", crawlCmd.Flags().Lookup("randomdelay")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().StringSlice("domainlimit", nil, "Per-domain limits as glob=parallelism[/delay[/randomdelay]], e.g. \"*cp24.com=1/5s/2s\"")
+	if err := viper.BindPFlag("domainlimit", crawlCmd.Flags().Lookup("domainlimit")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().Duration("maxduration", 0, "Stop crawling after this long (0 for no limit)")
+	if err := viper.BindPFlag("maxduration", crawlCmd.Flags().Lookup("maxduration")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().Int("maxpages", 0, "Stop crawling after this many requests (0 for no limit)")
+	if err := viper.BindPFlag("maxpages", crawlCmd.Flags().Lookup("maxpages")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().StringP("query", "q", "", "Boolean search query, e.g. \"(opioid OR fentanyl) AND toronto -sports\"")
+	if err := viper.BindPFlag("query", crawlCmd.Flags().Lookup("query")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().Bool("matchcontent", false, "Also match search terms against the title and main content of visited pages")
+	if err := viper.BindPFlag("matchcontent", crawlCmd.Flags().Lookup("matchcontent")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().String("similaritymetric", matcher.MetricSmithWatermanGotoh, "Similarity metric for terms that do not match exactly: swg, jaro-winkler, levenshtein or jaccard")
+	if err := viper.BindPFlag("similaritymetric", crawlCmd.Flags().Lookup("similaritymetric")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().Float64("similaritythreshold", 0, "Similarity from 0 to 1 above which terms match (0 for the default of the metric, or the threshold of each matcher)")
+	if err := viper.BindPFlag("similaritythreshold", crawlCmd.Flags().Lookup("similaritythreshold")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().String("language", "", "Language of the site, \"en\", \"fr\" or \"es\" (detected for each page if empty)")
+	if err := viper.BindPFlag("language", crawlCmd.Flags().Lookup("language")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().Bool("incremental", false, "Do not visit the pages earlier crawls of the site visited or matched again")
+	if err := viper.BindPFlag("incremental", crawlCmd.Flags().Lookup("incremental")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().StringSlice("feeds", nil, "Sitemaps, news sitemaps or RSS/Atom feeds whose pages are crawled too")
+	if err := viper.BindPFlag("feeds", crawlCmd.Flags().Lookup("feeds")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().Bool("discoverfeeds", false, "Also crawl the pages of the sitemaps in robots.txt and the feeds the start page links to")
+	if err := viper.BindPFlag("discoverfeeds", crawlCmd.Flags().Lookup("discoverfeeds")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().StringSlice("matchers", nil, "Topic matchers to enable, e.g. \"drug,mining\"")
+	if err := viper.BindPFlag("matchers", crawlCmd.Flags().Lookup("matchers")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
+	crawlCmd.Flags().String("resume", "", "ID of an interrupted crawl run to resume with its own options, see \"runs\"")
+	if err := viper.BindPFlag("resume", crawlCmd.Flags().Lookup("resume")); err != nil {
+		fmt.Println("Error binding flag", err)
+	}
+
 	return crawlCmd
 }
 
 func runCrawlCmd(
+	ctx context.Context,
 	manager crawler.CrawlManagerInterface,
 ) error {
 	// Check if manager is nil
@@ -58,6 +172,11 @@ func runCrawlCmd(
 		return errors.New("logger is nil")
 	}
 
+	if runID := viper.GetString("resume"); runID != "" {
+		logger.Info("Resuming crawl run " + runID)
+		return crawlResult(logger, manager.ResumeCrawl(ctx, runID))
+	}
+
 	options, err := getCrawlOptions()
 	if err != nil {
 		logger.Error("Error getting options", err)
@@ -67,13 +186,30 @@ func runCrawlCmd(
 	// Print options if Debug is enabled
 	if options.Debug {
 		logger.Info("CrawlOptions:")
+		logger.Info(fmt.Sprintf("  AllowedDomains: %v", options.AllowedDomains))
 		logger.Info(fmt.Sprintf("  CrawlSiteID: %s", options.CrawlSiteID))
 		logger.Info(fmt.Sprintf("  Debug: %t", options.Debug))
 		logger.Info(fmt.Sprintf("  DelayBetweenRequests: %s", options.DelayBetweenRequests.String()))
+		logger.Info(fmt.Sprintf("  DiscoverFeeds: %t", options.DiscoverFeeds))
+		logger.Info(fmt.Sprintf("  DomainLimits: %v", options.DomainLimits))
+		logger.Info(fmt.Sprintf("  Exclude: %v", options.Exclude))
+		logger.Info(fmt.Sprintf("  Feeds: %v", options.Feeds))
+		logger.Info(fmt.Sprintf("  Include: %v", options.Include))
+		logger.Info(fmt.Sprintf("  Incremental: %t", options.Incremental))
+		logger.Info(fmt.Sprintf("  Language: %s", options.Language))
+		logger.Info(fmt.Sprintf("  Matchers: %v", options.Matchers))
+		logger.Info(fmt.Sprintf("  MatchContent: %t", options.MatchContent))
 		logger.Info(fmt.Sprintf("  MaxConcurrentRequests: %d", options.MaxConcurrentRequests))
 		logger.Info(fmt.Sprintf("  MaxDepth: %d", options.MaxDepth))
+		logger.Info(fmt.Sprintf("  MaxDuration: %s", options.MaxDuration.String()))
+		logger.Info(fmt.Sprintf("  MaxPages: %d", options.MaxPages))
+		logger.Info(fmt.Sprintf("  Query: %s", options.Query))
+		logger.Info(fmt.Sprintf("  RandomDelay: %s", options.RandomDelay.String()))
 		logger.Info(fmt.Sprintf("  SearchTerms: %v", options.SearchTerms))
+		logger.Info(fmt.Sprintf("  SimilarityMetric: %s", options.SimilarityMetric))
+		logger.Info(fmt.Sprintf("  SimilarityThreshold: %v", options.SimilarityThreshold))
 		logger.Info(fmt.Sprintf("  StartURL: %s", options.StartURL))
+		logger.Info(fmt.Sprintf("  StartURLs: %v", options.StartURLs))
 	}
 
 	// Call SetOptions to update the manager's options
@@ -85,7 +221,16 @@ func runCrawlCmd(
 
 	logger.Info("Starting crawling")
 
-	err = manager.Crawl()
+	return crawlResult(logger, manager.Crawl(ctx))
+}
+
+// crawlResult logs the outcome of a crawl. A cancelled crawl is not an error,
+// it can be resumed.
+func crawlResult(logger loggo.LoggerInterface, err error) error {
+	if errors.Is(err, context.Canceled) {
+		logger.Info("Crawling cancelled, resume it with --resume and the ID of its run")
+		return nil
+	}
 	if err != nil {
 		logger.Error("Error starting crawling", err)
 		return err
@@ -99,13 +244,75 @@ func getCrawlOptions() (*crawler.CrawlOptions, error) {
 	options := &crawler.CrawlOptions{}
 
 	// Populate CrawlOptions fields
+	options.AllowedDomains = viper.GetStringSlice("alloweddomains")
 	options.CrawlSiteID = viper.GetString("siteid")
 	options.Debug = debug
 	options.DelayBetweenRequests = viper.GetDuration("delaybetweenrequests")
+	options.DiscoverFeeds = viper.GetBool("discoverfeeds")
+	options.Exclude = viper.GetStringSlice("exclude")
+	options.Feeds = viper.GetStringSlice("feeds")
+	options.Include = viper.GetStringSlice("include")
+	options.Incremental = viper.GetBool("incremental")
+	options.Language = viper.GetString("language")
+	options.Matchers = viper.GetStringSlice("matchers")
+	options.MatchContent = viper.GetBool("matchcontent")
 	options.MaxConcurrentRequests = viper.GetInt("maxconcurrentrequests")
 	options.MaxDepth = viper.GetInt("maxdepth")
-	options.SearchTerms = viper.GetStringSlice("searchterms")
-	options.StartURL = viper.GetString("url")
+	options.MaxDuration = viper.GetDuration("maxduration")
+	options.MaxPages = viper.GetInt("maxpages")
+	options.Query = viper.GetString("query")
+	options.RandomDelay = viper.GetDuration("randomdelay")
+	options.SearchTerms = termmatcher.ParseSearchTerms(viper.GetString("searchterms"))
+	options.SimilarityMetric = viper.GetString("similaritymetric")
+	options.SimilarityThreshold = viper.GetFloat64("similaritythreshold")
+
+	urls := viper.GetStringSlice("url")
+	if file := viper.GetString("urlfile"); file != "" {
+		fileURLs, err := readURLFile(file)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, fileURLs...)
+	}
+	if len(urls) > 0 {
+		options.StartURL = urls[0]
+		options.StartURLs = urls[1:]
+	}
+
+	domainLimits, err := crawler.ParseDomainLimits(viper.GetStringSlice("domainlimit"))
+	if err != nil {
+		return nil, err
+	}
+	options.DomainLimits = domainLimits
+
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
 
 	return options, nil
 }
+
+// readURLFile reads the URLs of a file, one per line. Blank lines and lines
+// starting with # are skipped.
+func readURLFile(name string) ([]string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open URL file: %v", err)
+	}
+	defer file.Close()
+
+	var urls []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read URL file: %v", err)
+	}
+
+	return urls, nil
+}
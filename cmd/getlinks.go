@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/jonesrussell/page-prowler/crawler"
 	"github.com/jonesrussell/page-prowler/internal/consumer"
@@ -13,16 +14,33 @@ import (
 
 // NewGetLinksCmd creates a new getlinks command
 func NewGetLinksCmd(manager crawler.CrawlManagerInterface) *cobra.Command {
+	var sortBy string
+	var minScore float64
+	var since string
+	var newOnly bool
+
 	getLinksCmd := &cobra.Command{
 		Use:   "getlinks",
 		Short: "Get the list of links for a given siteid",
+		Long: `Get the list of links for a given siteid. --since only lists the links
+first found at or after a time, and --new-only those first found by the
+latest completed crawl of the site.`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			siteid := viper.GetString("siteid")
 			if siteid == "" {
 				return ErrSiteidRequired
 			}
 
-			output, err := printLinks(cmd.Context(), manager, siteid)
+			filter := linkFilter{minScore: minScore, newOnly: newOnly}
+			if since != "" {
+				var err error
+				filter.since, err = consumer.ParseSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+			}
+
+			output, err := printLinks(cmd.Context(), manager, siteid, sortBy, filter)
 			if err != nil {
 				log.Printf("Failed to print links: %v\n", err)
 				return err
@@ -43,6 +61,11 @@ func NewGetLinksCmd(manager crawler.CrawlManagerInterface) *cobra.Command {
 		},
 	}
 
+	getLinksCmd.Flags().StringVar(&sortBy, "sort", consumer.SortURL, "Sort links by \"score\" or \"url\"")
+	getLinksCmd.Flags().Float64Var(&minScore, "minscore", 0, "Only show links with at least this relevance, from 0 to 1")
+	getLinksCmd.Flags().StringVar(&since, "since", "", "Only show links first found at or after this time, e.g. \"2024-03-05\", an RFC 3339 time or \"24h\" ago")
+	getLinksCmd.Flags().BoolVar(&newOnly, "new-only", false, "Only show links first found by the latest completed crawl of the site")
+
 	return getLinksCmd
 }
 
@@ -54,12 +77,34 @@ func printJSON(jsonOutput []byte) error {
 	return nil
 }
 
-func printLinks(ctx context.Context, manager crawler.CrawlManagerInterface, siteid string) (consumer.Output, error) {
+// linkFilter selects the links getlinks lists.
+type linkFilter struct {
+	minScore float64
+	since    time.Time // zero for all links
+	newOnly  bool      // only the links found by the latest completed crawl
+}
+
+func printLinks(ctx context.Context, manager crawler.CrawlManagerInterface, siteid, sortBy string, filter linkFilter) (consumer.Output, error) {
 	links, err := consumer.RetrieveAndUnmarshalLinks(ctx, manager, siteid)
 	if err != nil {
 		return consumer.Output{}, err
 	}
 
+	links = consumer.FilterLinks(links, filter.minScore)
+	if !filter.since.IsZero() {
+		links = consumer.FilterSince(links, filter.since)
+	}
+	if filter.newOnly {
+		runID, err := consumer.LatestRunID(ctx, manager, siteid)
+		if err != nil {
+			return consumer.Output{}, err
+		}
+		links = consumer.FilterRun(links, runID)
+	}
+	if err := consumer.SortLinks(links, sortBy); err != nil {
+		return consumer.Output{}, err
+	}
+
 	output := consumer.CreateOutput(siteid, links)
 	return output, nil
 }
@@ -0,0 +1,367 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/jonesrussell/page-prowler/internal/matcher"
+	"github.com/jonesrussell/page-prowler/internal/termmatcher"
+	"github.com/jonesrussell/page-prowler/models"
+	"github.com/spf13/cobra"
+)
+
+// ErrMatchTermsRequired is returned when there is nothing to match links with.
+var ErrMatchTermsRequired = errors.New("searchterms, query or matchers is required")
+
+// matchOptions are the flags that choose how the match commands match,
+// as the crawl flags of the same names do.
+type matchOptions struct {
+	searchTerms         string
+	query               string
+	language            string
+	similarityMetric    string
+	similarityThreshold float64
+	matchers            []string
+}
+
+func (o *matchOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.searchTerms, "searchterms", "t", "", "Comma separated search terms; quote a phrase to match it exactly")
+	cmd.Flags().StringVarP(&o.query, "query", "q", "", "Boolean search query, e.g. \"(opioid OR fentanyl) AND toronto -sports\"")
+	cmd.Flags().StringVar(&o.similarityMetric, "similaritymetric", matcher.MetricSmithWatermanGotoh, "Similarity metric for terms that do not match exactly: swg, jaro-winkler, levenshtein or jaccard")
+	cmd.Flags().Float64Var(&o.similarityThreshold, "similaritythreshold", 0, "Similarity from 0 to 1 above which terms match (0 for the default of the metric, or the threshold of each matcher)")
+	cmd.Flags().StringVar(&o.language, "language", "", "Language of the links, \"en\", \"fr\" or \"es\" (default \"en\")")
+	cmd.Flags().StringSliceVar(&o.matchers, "matchers", nil, "Topic matchers to enable, e.g. \"drug,mining\"")
+}
+
+// crawlOptions returns the options of a crawl of siteid that matches as the
+// options do.
+func (o *matchOptions) crawlOptions(siteid string) *crawler.CrawlOptions {
+	return &crawler.CrawlOptions{
+		CrawlSiteID:         siteid,
+		Language:            o.language,
+		Matchers:            o.matchers,
+		Query:               o.query,
+		SearchTerms:         termmatcher.ParseSearchTerms(o.searchTerms),
+		SimilarityMetric:    o.similarityMetric,
+		SimilarityThreshold: o.similarityThreshold,
+	}
+}
+
+// linkMatcher returns the LinkMatcher of manager that matches as a crawl of
+// siteid with the options does. Without search terms or a query, the query
+// saved for siteid is used.
+func (o *matchOptions) linkMatcher(ctx context.Context, manager crawler.CrawlManagerInterface, siteid string) (*crawler.LinkMatcher, error) {
+	if manager == nil {
+		return nil, errors.New("manager is nil")
+	}
+
+	links, err := manager.NewLinkMatcher(ctx, o.crawlOptions(siteid))
+	if errors.Is(err, crawler.ErrNoSearchTerms) {
+		return nil, ErrMatchTermsRequired
+	}
+	return links, err
+}
+
+// NewMatchCmd creates a new match command
+func NewMatchCmd(manager crawler.CrawlManagerInterface) *cobra.Command {
+	var options matchOptions
+	var siteid string
+
+	matchCmd := &cobra.Command{
+		Use:   "match [file...]",
+		Short: "Match links against search terms without crawling",
+		Long: `Match a list of links, read from the files given or the standard input, with
+search terms, a query or topic matchers as a crawl does, without fetching
+them. Each line holds a URL, optionally followed by a tab or a space and the
+anchor text of the link. Blank lines and lines starting with "#" are skipped.
+
+The matching links are written as JSON lines of page data or, with --siteid,
+saved as results of the site. With --siteid and no search terms or query, the
+query saved for the site is used. For example:
+
+  page-prowler match --searchterms "fentanyl,opioid" urls.txt
+  cat urls.txt | page-prowler match --siteid news --query "(opioid OR fentanyl) -sports"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			links, err := options.linkMatcher(cmd.Context(), manager, siteid)
+			if err != nil {
+				return err
+			}
+
+			inputs, err := openMatchInputs(cmd.InOrStdin(), args)
+			if err != nil {
+				return err
+			}
+			defer closeMatchInputs(inputs)
+
+			return runMatchCmd(cmd.Context(), cmd.OutOrStdout(), manager.GetDBManager(), links, inputs, siteid)
+		},
+	}
+
+	matchCmd.Flags().StringVarP(&siteid, "siteid", "s", "", "Site ID to save the matching links under instead of printing them")
+	options.addFlags(matchCmd)
+
+	matchCmd.AddCommand(newMatchExplainCmd(manager))
+
+	return matchCmd
+}
+
+func newMatchExplainCmd(manager crawler.CrawlManagerInterface) *cobra.Command {
+	var options matchOptions
+	var siteid, href, anchorText, output string
+
+	explainCmd := &cobra.Command{
+		Use:   "explain",
+		Short: "Explain why a link does or does not match",
+		Long: `Match a link against search terms, a query or topic matchers and print each
+step: the slug taken from the URL, the text without stopwords, the stemmed
+words and, for every term, whether it is exactly in the content and its best
+similarity score against the threshold. With --siteid and no search terms or
+query, the query saved for the site is used. For example:
+
+  page-prowler match explain --url https://example.com/news/fentanyl-seized --anchor "Police seize drugs" --searchterms "fentanyl,drug policy"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runMatchExplainCmd(cmd.Context(), cmd.OutOrStdout(), manager, &options, siteid, href, anchorText, output)
+		},
+	}
+
+	explainCmd.Flags().StringVarP(&siteid, "siteid", "s", "", "Site ID whose saved query to use without search terms or a query")
+	explainCmd.Flags().StringVarP(&href, "url", "u", "", "URL of the link")
+	explainCmd.Flags().StringVarP(&anchorText, "anchor", "a", "", "Anchor text of the link")
+	explainCmd.Flags().StringVarP(&output, "output", "o", OutputTable, "Output format: \"table\" or \"json\"")
+	options.addFlags(explainCmd)
+
+	return explainCmd
+}
+
+// matchInput is a named list of links.
+type matchInput struct {
+	name string
+	r    io.Reader
+}
+
+// openMatchInputs opens the files named by args, or returns stdin if there
+// are none. "-" is stdin too.
+func openMatchInputs(stdin io.Reader, args []string) ([]matchInput, error) {
+	if len(args) == 0 {
+		return []matchInput{{name: "stdin", r: stdin}}, nil
+	}
+
+	var inputs []matchInput
+	for _, name := range args {
+		if name == "-" {
+			inputs = append(inputs, matchInput{name: "stdin", r: stdin})
+			continue
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			closeMatchInputs(inputs)
+			return nil, fmt.Errorf("failed to open links: %v", err)
+		}
+		inputs = append(inputs, matchInput{name: name, r: f})
+	}
+	return inputs, nil
+}
+
+func closeMatchInputs(inputs []matchInput) {
+	for _, input := range inputs {
+		if f, ok := input.r.(*os.File); ok && f != os.Stdin {
+			f.Close()
+		}
+	}
+}
+
+// runMatchCmd matches the links of inputs and writes the matches as JSON
+// lines to w or, if siteid is set, saves them as results of the site. Saved
+// matches are found by a crawl run of their own, as the results of a crawl
+// are, for getlinks --since and --new-only to list them.
+func runMatchCmd(ctx context.Context, w io.Writer, dbManager dbmanager.DatabaseManagerInterface, links *crawler.LinkMatcher, inputs []matchInput, siteid string) error {
+	var results []models.PageData
+	total := 0
+
+	encoder := json.NewEncoder(w)
+	for _, input := range inputs {
+		scanner := bufio.NewScanner(input.r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+		for line := 1; scanner.Scan(); line++ {
+			href, anchorText, ok := parseMatchLine(scanner.Text())
+			if !ok {
+				continue
+			}
+			if u, err := url.Parse(href); err != nil || !u.IsAbs() {
+				return fmt.Errorf("%s:%d: invalid URL %q", input.name, line, href)
+			}
+			total++
+
+			pageData, matched := links.Match(href, anchorText)
+			if !matched {
+				continue
+			}
+			if siteid != "" {
+				results = append(results, pageData)
+				continue
+			}
+			if err := encoder.Encode(pageData); err != nil {
+				return err
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read %s: %v", input.name, err)
+		}
+	}
+
+	if siteid == "" {
+		return nil
+	}
+
+	if err := saveMatches(ctx, dbManager, siteid, results, total); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%d of %d links matched, saved for %s\n", len(results), total, siteid)
+	return nil
+}
+
+// saveMatches saves the matches of total links as results of the site, found
+// by a new crawl run.
+func saveMatches(ctx context.Context, dbManager dbmanager.DatabaseManagerInterface, siteid string, results []models.PageData, total int) error {
+	run := &models.CrawlRun{
+		ID:              uuid.NewString(),
+		SiteID:          siteid,
+		Status:          models.CrawlRunStatusCompleted,
+		StartedAt:       time.Now().UTC(),
+		TotalLinks:      total,
+		MatchedLinks:    len(results),
+		NotMatchedLinks: total - len(results),
+	}
+
+	for i := range results {
+		started := run.StartedAt
+		results[i].FirstSeen = &started
+		results[i].RunID = run.ID
+	}
+
+	if len(results) > 0 {
+		if err := dbManager.SaveResults(ctx, results, siteid); err != nil {
+			return fmt.Errorf("failed to save results: %v", err)
+		}
+	}
+
+	ended := time.Now().UTC()
+	run.EndedAt = &ended
+	if err := dbManager.SaveCrawlRun(ctx, run); err != nil {
+		return fmt.Errorf("failed to save crawl run: %v", err)
+	}
+	return nil
+}
+
+// parseMatchLine splits a line of a list of links into the URL and the
+// anchor text. It reports false for blank lines and comments.
+func parseMatchLine(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+
+	i := strings.IndexFunc(line, unicode.IsSpace)
+	if i < 0 {
+		return line, "", true
+	}
+	return line[:i], strings.TrimSpace(line[i+1:]), true
+}
+
+func runMatchExplainCmd(ctx context.Context, w io.Writer, manager crawler.CrawlManagerInterface, options *matchOptions, siteid, href, anchorText, output string) error {
+	if href == "" {
+		return errors.New("url is required")
+	}
+
+	links, err := options.linkMatcher(ctx, manager, siteid)
+	if err != nil {
+		return err
+	}
+	explanation := links.Explain(href, anchorText)
+
+	switch output {
+	case OutputTable:
+		return writeExplanation(w, explanation)
+	case OutputJSON:
+		return writeIndentedJSON(w, explanation)
+	default:
+		return fmt.Errorf("unknown output format: %s", output)
+	}
+}
+
+func writeExplanation(w io.Writer, e *termmatcher.Explanation) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "URL:\t%s\n", e.URL)
+	fmt.Fprintf(tw, "Language:\t%s\n", e.Language)
+	for _, text := range []struct {
+		name string
+		termmatcher.TextExplanation
+	}{{"Slug", e.Slug}, {"Anchor text", e.AnchorText}} {
+		fmt.Fprintf(tw, "%s:\t%s\n", text.name, text.Text)
+		fmt.Fprintf(tw, "  without stopwords:\t%s\n", text.WithoutStopwords)
+		fmt.Fprintf(tw, "  stemmed:\t%s\n", text.Stemmed)
+	}
+	fmt.Fprintf(tw, "Content:\t%s\n", e.Content)
+	if e.TooShort {
+		fmt.Fprintln(tw, "  too short to match")
+	}
+	fmt.Fprintf(tw, "Similarity:\t%s, threshold %.2f\n", e.Metric, e.Threshold)
+	if e.Query != "" {
+		fmt.Fprintf(tw, "Query:\t%s (%s)\n", e.Query, yesNo(e.QueryMatched, "matches", "does not match"))
+	}
+	fmt.Fprintln(tw)
+
+	if len(e.Terms) > 0 {
+		fmt.Fprintln(tw, "TERM\tPROCESSED\tEXACT\tSCORE\tBEST MATCH\tMATCHED")
+		for _, term := range e.Terms {
+			name := term.Term
+			if term.Quoted {
+				name = `"` + name + `"`
+			}
+			if term.Excluded {
+				name = "-" + name
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%.2f\t%s\t%s\n", name, term.Processed, yesNo(term.ExactMatch, "yes", "no"), term.Score, term.BestMatch, yesNo(term.Matched, "yes", "no"))
+		}
+		fmt.Fprintln(tw)
+	}
+
+	if len(e.Topics) > 0 {
+		fmt.Fprintln(tw, "TOPIC\tMATCHED")
+		for _, topic := range e.Topics {
+			fmt.Fprintf(tw, "%s\t%s\n", topic.Name, yesNo(topic.Matched, "yes", "no"))
+		}
+		fmt.Fprintln(tw)
+	}
+
+	if e.Matched {
+		fmt.Fprintf(tw, "Result:\tmatched %s\n", strings.Join(e.MatchingTerms, ", "))
+	} else {
+		fmt.Fprintln(tw, "Result:\tnot matched")
+	}
+
+	return tw.Flush()
+}
+
+func yesNo(b bool, yes, no string) string {
+	if b {
+		return yes
+	}
+	return no
+}
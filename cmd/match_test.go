@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonesrussell/loggo"
+	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/jonesrussell/page-prowler/internal/termmatcher"
+	"github.com/jonesrussell/page-prowler/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMatchTestManager(t *testing.T) *crawler.CrawlManager {
+	t.Helper()
+
+	logger := loggo.NewMockLoggerInterface(gomock.NewController(t))
+	logger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+	return crawler.NewCrawlManager(logger, dbmanager.NewMockDBManager(), nil, nil)
+}
+
+func TestRunMatchExplainCmd(t *testing.T) {
+	manager := newMatchTestManager(t)
+	const url = "https://example.com/news/fentanyl-seized-at-the-border"
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		options := &matchOptions{searchTerms: `fentanyl,"drug policy"`}
+		require.NoError(t, runMatchExplainCmd(context.Background(), &buf, manager, options, "", url, "Police seize drugs", OutputTable))
+
+		out := buf.String()
+		assert.Contains(t, out, "fentanyl seiz border")
+		assert.Contains(t, out, `"drug policy"`)
+		assert.Contains(t, out, "Result:  matched fentanyl")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		options := &matchOptions{query: "fentanyl -toronto", similarityMetric: "levenshtein"}
+		require.NoError(t, runMatchExplainCmd(context.Background(), &buf, manager, options, "", url, "", OutputJSON))
+
+		var explanation termmatcher.Explanation
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &explanation))
+		assert.Equal(t, "levenshtein", explanation.Metric)
+		assert.Equal(t, 0.8, explanation.Threshold)
+		assert.True(t, explanation.QueryMatched)
+		assert.Equal(t, []string{"fentanyl"}, explanation.MatchingTerms)
+		assert.Len(t, explanation.Terms, 2)
+	})
+
+	t.Run("saved query", func(t *testing.T) {
+		manager := newMatchTestManager(t)
+		manager.DBManager.(*dbmanager.MockDBManager).Queries = map[string]string{"news": "fentanyl -hockey"}
+
+		var buf bytes.Buffer
+		require.NoError(t, runMatchExplainCmd(context.Background(), &buf, manager, &matchOptions{}, "news", url, "", OutputJSON))
+
+		var explanation termmatcher.Explanation
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &explanation))
+		assert.Equal(t, "fentanyl -hockey", explanation.Query)
+		assert.True(t, explanation.QueryMatched)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Error(t, runMatchExplainCmd(context.Background(), &buf, manager, &matchOptions{searchTerms: "fire"}, "", "", "", OutputTable))
+		assert.ErrorIs(t, runMatchExplainCmd(context.Background(), &buf, manager, &matchOptions{}, "", url, "", OutputTable), ErrMatchTermsRequired)
+		assert.Error(t, runMatchExplainCmd(context.Background(), &buf, manager, &matchOptions{query: "(fire"}, "", url, "", OutputTable))
+		assert.Error(t, runMatchExplainCmd(context.Background(), &buf, manager, &matchOptions{searchTerms: "fire", similarityMetric: "cosine"}, "", url, "", OutputTable))
+		assert.Error(t, runMatchExplainCmd(context.Background(), &buf, manager, &matchOptions{searchTerms: "fire", matchers: []string{"unknown"}}, "", url, "", OutputTable))
+		assert.Error(t, runMatchExplainCmd(context.Background(), &buf, manager, &matchOptions{searchTerms: "fire"}, "", url, "", "xml"))
+	})
+}
+
+const matchTestLinks = `# exported links
+https://example.com/news/fentanyl-seized-at-the-border
+https://example.com/news/1.7051216	Opioid crisis deepens
+
+https://example.com/sports/hockey-night Hockey night in Toronto
+`
+
+func TestMatchCmd(t *testing.T) {
+	t.Run("json lines", func(t *testing.T) {
+		cmd := NewMatchCmd(newMatchTestManager(t))
+		cmd.SetIn(strings.NewReader(matchTestLinks))
+
+		output, err := ExecuteCommand(cmd, "--searchterms", "fentanyl,opioid")
+		require.NoError(t, err)
+
+		var results []models.PageData
+		scanner := bufio.NewScanner(strings.NewReader(output))
+		for scanner.Scan() {
+			var pageData models.PageData
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &pageData))
+			results = append(results, pageData)
+		}
+
+		if assert.Len(t, results, 2) {
+			assert.Equal(t, "https://example.com/news/fentanyl-seized-at-the-border", results[0].URL)
+			assert.Equal(t, []string{"fentanyl"}, results[0].MatchingTerms)
+			assert.Equal(t, []string{models.MatchLocationURL}, results[0].MatchLocations)
+			assert.Greater(t, results[0].Relevance, 0.0)
+			assert.Equal(t, "https://example.com/news/1.7051216", results[1].URL)
+			assert.Equal(t, []string{"opioid"}, results[1].MatchingTerms)
+			assert.Equal(t, []string{models.MatchLocationAnchor}, results[1].MatchLocations)
+		}
+	})
+
+	t.Run("save with the saved query", func(t *testing.T) {
+		manager := newMatchTestManager(t)
+		dbManager := manager.DBManager.(*dbmanager.MockDBManager)
+		dbManager.Queries = map[string]string{"news": "(toronto OR fentanyl) -hockey"}
+
+		cmd := NewMatchCmd(manager)
+		cmd.SetIn(strings.NewReader(matchTestLinks))
+
+		output, err := ExecuteCommand(cmd, "--siteid", "news", "-")
+		require.NoError(t, err)
+		assert.Equal(t, "1 of 3 links matched, saved for news\n", output)
+
+		runs, err := dbManager.ListCrawlRuns(context.Background(), "news")
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, models.CrawlRunStatusCompleted, runs[0].Status)
+		assert.Equal(t, 3, runs[0].TotalLinks)
+		assert.Equal(t, 1, runs[0].MatchedLinks)
+
+		// Stamped like the results of a crawl
+		if assert.Len(t, dbManager.SavedResults, 1) {
+			result := dbManager.SavedResults[0]
+			assert.Equal(t, "https://example.com/news/fentanyl-seized-at-the-border", result.URL)
+			assert.Equal(t, runs[0].ID, result.RunID)
+			require.NotNil(t, result.FirstSeen)
+			assert.True(t, runs[0].StartedAt.Equal(*result.FirstSeen))
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := ExecuteCommand(NewMatchCmd(newMatchTestManager(t)))
+		assert.ErrorIs(t, err, ErrMatchTermsRequired)
+
+		cmd := NewMatchCmd(newMatchTestManager(t))
+		cmd.SetIn(strings.NewReader("/news/fentanyl-seized\n"))
+		_, err = ExecuteCommand(cmd, "--searchterms", "fentanyl")
+		assert.ErrorContains(t, err, "stdin:1: invalid URL")
+
+		_, err = ExecuteCommand(NewMatchCmd(newMatchTestManager(t)), "--searchterms", "fentanyl", "missing.txt")
+		assert.Error(t, err)
+	})
+}
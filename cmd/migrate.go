@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// NewMigrateCmd creates a new migrate command
+func NewMigrateCmd(manager crawler.CrawlManagerInterface) *cobra.Command {
+	var siteids []string
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Convert the saved links of the given siteids to one record per URL",
+		Long: `Older versions saved the links of a site in Redis as a set of JSON
+documents, so the same URL could be saved more than once. migrate converts
+them to one record per URL, merging the matching terms of duplicates, and
+deletes the old set.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if manager == nil {
+				return errors.New("manager is nil")
+			}
+
+			if len(siteids) == 0 {
+				if siteid := viper.GetString("siteid"); siteid != "" {
+					siteids = []string{siteid}
+				}
+			}
+			if len(siteids) == 0 {
+				return ErrSiteidRequired
+			}
+
+			return runMigrateCmd(cmd.Context(), cmd.OutOrStdout(), manager.GetDBManager(), siteids)
+		},
+	}
+
+	migrateCmd.Flags().StringSliceVarP(&siteids, "siteid", "s", nil, "Site IDs to migrate")
+
+	return migrateCmd
+}
+
+func runMigrateCmd(ctx context.Context, w io.Writer, dbManager dbmanager.DatabaseManagerInterface, siteids []string) error {
+	migrator, ok := dbManager.(dbmanager.ResultsMigrator)
+	if !ok {
+		return errors.New("the storage backend has no results of older versions to migrate")
+	}
+
+	for _, siteid := range siteids {
+		migrated, err := migrator.MigrateResults(ctx, siteid)
+		if err != nil {
+			return fmt.Errorf("failed to migrate results of %s: %v", siteid, err)
+		}
+		fmt.Fprintf(w, "%s: migrated %d results\n", siteid, migrated)
+	}
+
+	return nil
+}
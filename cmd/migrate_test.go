@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/jonesrussell/loggo"
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/jonesrussell/page-prowler/internal/prowlredis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMigrateCmd(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	client, err := prowlredis.NewClient(ctx, &prowlredis.Options{Addr: mr.Addr()})
+	require.NoError(t, err)
+
+	logger := loggo.NewMockLoggerInterface(gomock.NewController(t))
+	logger.EXPECT().Debug(gomock.Any(), gomock.Any()).AnyTimes()
+	dbManager := dbmanager.NewRedisManager(client, logger)
+
+	// Results saved by older versions
+	_, err = mr.SAdd("site",
+		`{"url":"https://example.com/a","matching_terms":["fire"],"similarity_score":0.9}`,
+		`{"url":"https://example.com/a","matching_terms":["police"],"similarity_score":0.4}`,
+	)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, runMigrateCmd(ctx, &buf, dbManager, []string{"site", "empty"}))
+	assert.Equal(t, "site: migrated 2 results\nempty: migrated 0 results\n", buf.String())
+
+	result, err := dbManager.GetResult(ctx, "site", "https://example.com/a")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"fire", "police"}, result.MatchingTerms)
+	assert.False(t, mr.Exists("site"))
+
+	// The mock backend has no old results to migrate
+	assert.Error(t, runMigrateCmd(ctx, &buf, dbmanager.NewMockDBManager(), []string{"site"}))
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/jonesrussell/page-prowler/internal/termmatcher"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// NewQueryCmd creates a new query command
+func NewQueryCmd(manager crawler.CrawlManagerInterface) *cobra.Command {
+	var siteid string
+
+	// dbManager returns the storage of the manager and the site ID to use
+	dbManager := func() (dbmanager.DatabaseManagerInterface, string, error) {
+		if manager == nil {
+			return nil, "", errors.New("manager is nil")
+		}
+
+		if siteid == "" {
+			siteid = viper.GetString("siteid")
+		}
+		if siteid == "" {
+			return nil, "", ErrSiteidRequired
+		}
+
+		return manager.GetDBManager(), siteid, nil
+	}
+
+	queryCmd := &cobra.Command{
+		Use:   "query",
+		Short: "Show the search query saved for a given siteid",
+		Long: `A site can have a saved search query, which crawls of the site use when
+they are given neither --searchterms nor --query. Queries combine terms with
+AND, OR and NOT, for example:
+
+  (opioid OR fentanyl) AND toronto -sports
+
+Terms next to each other must all match. A leading "-" excludes a term,
+parentheses group and double quotes match an exact phrase. Inside an OR, a
+leading "+" makes an alternative required: "fire OR +flood" only matches
+when flood does, and fire is reported when it is found too.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dm, siteid, err := dbManager()
+			if err != nil {
+				return err
+			}
+			return runQueryShowCmd(cmd.Context(), cmd.OutOrStdout(), dm, siteid)
+		},
+	}
+
+	queryCmd.PersistentFlags().StringVarP(&siteid, "siteid", "s", "", "Site ID of the query")
+
+	queryCmd.AddCommand(&cobra.Command{
+		Use:   "set <query>",
+		Short: "Save the search query for a given siteid",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dm, siteid, err := dbManager()
+			if err != nil {
+				return err
+			}
+			return runQuerySetCmd(cmd.Context(), cmd.OutOrStdout(), dm, siteid, args[0])
+		},
+	})
+
+	queryCmd.AddCommand(&cobra.Command{
+		Use:   "clear",
+		Short: "Delete the search query saved for a given siteid",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dm, siteid, err := dbManager()
+			if err != nil {
+				return err
+			}
+			return runQuerySetCmd(cmd.Context(), cmd.OutOrStdout(), dm, siteid, "")
+		},
+	})
+
+	return queryCmd
+}
+
+func runQueryShowCmd(ctx context.Context, w io.Writer, dbManager dbmanager.DatabaseManagerInterface, siteid string) error {
+	query, err := dbManager.GetQuery(ctx, siteid)
+	if err != nil {
+		return fmt.Errorf("failed to get query: %v", err)
+	}
+
+	if query == "" {
+		fmt.Fprintf(w, "No query saved for %s\n", siteid)
+		return nil
+	}
+
+	fmt.Fprintln(w, query)
+	return nil
+}
+
+// runQuerySetCmd saves query for siteid, or deletes the saved query if it is
+// empty.
+func runQuerySetCmd(ctx context.Context, w io.Writer, dbManager dbmanager.DatabaseManagerInterface, siteid, query string) error {
+	if query != "" {
+		if _, err := termmatcher.ParseQuery(query); err != nil {
+			return err
+		}
+	}
+
+	if err := dbManager.SaveQuery(ctx, siteid, query); err != nil {
+		return fmt.Errorf("failed to save query: %v", err)
+	}
+
+	if query == "" {
+		fmt.Fprintf(w, "Query for %s cleared\n", siteid)
+	} else {
+		fmt.Fprintf(w, "Query for %s saved\n", siteid)
+	}
+	return nil
+}
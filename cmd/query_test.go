@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/jonesrussell/page-prowler/internal/termmatcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunQueryCmds(t *testing.T) {
+	ctx := context.Background()
+	dbManager := dbmanager.NewMockDBManager()
+
+	var buf bytes.Buffer
+	require.NoError(t, runQueryShowCmd(ctx, &buf, dbManager, "site"))
+	assert.Equal(t, "No query saved for site\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, runQuerySetCmd(ctx, &buf, dbManager, "site", "(opioid OR fentanyl) AND toronto -sports"))
+	assert.Equal(t, "Query for site saved\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, runQueryShowCmd(ctx, &buf, dbManager, "site"))
+	assert.Equal(t, "(opioid OR fentanyl) AND toronto -sports\n", buf.String())
+
+	// An invalid query leaves the saved one alone
+	err := runQuerySetCmd(ctx, &buf, dbManager, "site", "(opioid OR")
+	assert.ErrorIs(t, err, termmatcher.ErrInvalidQuery)
+	assert.Equal(t, "(opioid OR fentanyl) AND toronto -sports", dbManager.Queries["site"])
+
+	buf.Reset()
+	require.NoError(t, runQuerySetCmd(ctx, &buf, dbManager, "site", ""))
+	assert.Equal(t, "Query for site cleared\n", buf.String())
+	assert.Empty(t, dbManager.Queries)
+}
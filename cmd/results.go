@@ -1,27 +1,219 @@
 package cmd
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
 	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/internal/consumer"
+	"github.com/jonesrussell/page-prowler/internal/stats"
+	"github.com/jonesrussell/page-prowler/models"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Output formats supported by the results command
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputCSV   = "csv"
 )
 
+// ResultsOutput is the JSON representation of the results command output.
+type ResultsOutput struct {
+	Siteid string            `json:"siteid"`
+	Stats  *stats.Stats      `json:"stats,omitempty"`
+	Pages  []models.PageData `json:"pages"`
+}
+
 // NewResultsCmd creates a new results command
-func NewResultsCmd(_ crawler.CrawlManagerInterface) *cobra.Command {
+func NewResultsCmd(manager crawler.CrawlManagerInterface) *cobra.Command {
+	var siteid, sortBy, term, output string
+	var minScore float64
+
 	resultsCmd := &cobra.Command{
 		Use:   "results",
-		Short: "A brief description of your command",
-		Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
-		RunE: func(_ *cobra.Command, _ []string) error {
-			// Your command execution logic goes here
-			// You can use similar logic as in your NewCrawlCmd function
-			return nil
+		Short: "Show the matched pages and crawl statistics for a given siteid",
+		Long: `Show the pages matched by the crawls of a site along with the statistics of the last crawl.
+
+Pages can be sorted by relevance score or URL, filtered by matching term or
+minimum relevance, and printed as a table, JSON or CSV. For example:
+
+  page-prowler results --siteid cp24 --sort score --minscore 0.5 --term police --output csv`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if siteid == "" {
+				siteid = viper.GetString("siteid")
+			}
+			if siteid == "" {
+				return ErrSiteidRequired
+			}
+
+			return runResultsCmd(cmd.Context(), cmd.OutOrStdout(), manager, siteid, sortBy, term, minScore, output)
 		},
 	}
 
+	resultsCmd.Flags().StringVarP(&siteid, "siteid", "s", "", "Site ID to show results for")
+	resultsCmd.Flags().StringVar(&sortBy, "sort", consumer.SortScore, "Sort pages by \"score\" or \"url\"")
+	resultsCmd.Flags().StringVar(&term, "term", "", "Only show pages that matched this term")
+	resultsCmd.Flags().Float64Var(&minScore, "minscore", 0, "Only show pages with at least this relevance, from 0 to 1")
+	resultsCmd.Flags().StringVarP(&output, "output", "o", OutputTable, "Output format: \"table\", \"json\" or \"csv\"")
+
 	return resultsCmd
 }
+
+func runResultsCmd(
+	ctx context.Context,
+	w io.Writer,
+	manager crawler.CrawlManagerInterface,
+	siteid, sortBy, term string,
+	minScore float64,
+	output string,
+) error {
+	// Check if manager is nil
+	if manager == nil {
+		fmt.Println("Error: manager is nil")
+		return errors.New("manager is nil")
+	}
+
+	dbManager := manager.GetDBManager()
+
+	pages, err := dbManager.GetResults(ctx, siteid)
+	if err != nil {
+		return fmt.Errorf("failed to get results: %v", err)
+	}
+
+	linkStats, err := dbManager.GetStats(ctx, siteid)
+	if err != nil {
+		return fmt.Errorf("failed to get stats: %v", err)
+	}
+
+	pages = filterPagesByTerm(pages, term)
+	pages = filterPagesByScore(pages, minScore)
+	if err := consumer.SortPages(pages, sortBy); err != nil {
+		return err
+	}
+	// The deprecated similarity score of old results only ranks them.
+	for i := range pages {
+		pages[i].SimilarityScore = 0
+	}
+
+	results := ResultsOutput{
+		Siteid: siteid,
+		Stats:  linkStats,
+		Pages:  pages,
+	}
+
+	switch output {
+	case OutputTable:
+		return writeResultsTable(w, results)
+	case OutputJSON:
+		return writeIndentedJSON(w, results)
+	case OutputCSV:
+		return writeResultsCSV(w, results)
+	default:
+		return fmt.Errorf("unknown output format: %s", output)
+	}
+}
+
+func filterPagesByTerm(pages []models.PageData, term string) []models.PageData {
+	if term == "" {
+		return pages
+	}
+
+	term = strings.ToLower(term)
+	filtered := make([]models.PageData, 0, len(pages))
+	for _, page := range pages {
+		for _, matchingTerm := range page.MatchingTerms {
+			if strings.ToLower(matchingTerm) == term {
+				filtered = append(filtered, page)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+func filterPagesByScore(pages []models.PageData, minScore float64) []models.PageData {
+	if minScore <= 0 {
+		return pages
+	}
+
+	filtered := make([]models.PageData, 0, len(pages))
+	for _, page := range pages {
+		if page.Relevance >= minScore {
+			filtered = append(filtered, page)
+		}
+	}
+	return filtered
+}
+
+func writeResultsTable(w io.Writer, results ResultsOutput) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if results.Stats != nil {
+		fmt.Fprintf(tw, "Total pages:\t%d\n", results.Stats.GetTotalPages())
+		fmt.Fprintf(tw, "Total links:\t%d\n", results.Stats.GetTotalLinks())
+		fmt.Fprintf(tw, "Matched links:\t%d\n", results.Stats.GetMatchedLinks())
+		fmt.Fprintf(tw, "Not matched links:\t%d\n", results.Stats.GetNotMatchedLinks())
+	} else {
+		fmt.Fprintf(tw, "No crawl statistics recorded for %s\n", results.Siteid)
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "SCORE\tURL\tMATCHING TERMS\tPUBLISHED\tTITLE")
+	for _, page := range results.Pages {
+		published := "-"
+		if page.PublishedTime != nil {
+			published = page.PublishedTime.Format("2006-01-02")
+		}
+		fmt.Fprintf(tw, "%.2f\t%s\t%s\t%s\t%s\n", page.Relevance, page.URL, strings.Join(page.MatchingTerms, ", "), published, page.Title)
+	}
+
+	return tw.Flush()
+}
+
+func writeIndentedJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func writeResultsCSV(w io.Writer, results ResultsOutput) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"url", "matching_terms", "relevance", "match_locations", "title", "description", "image", "canonical_url", "published_time"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, page := range results.Pages {
+		var published string
+		if page.PublishedTime != nil {
+			published = page.PublishedTime.Format(time.RFC3339)
+		}
+		record := []string{
+			page.URL,
+			strings.Join(page.MatchingTerms, ";"),
+			strconv.FormatFloat(page.Relevance, 'f', -1, 64),
+			strings.Join(page.MatchLocations, ";"),
+			page.Title,
+			page.Description,
+			page.Image,
+			page.CanonicalURL,
+			published,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
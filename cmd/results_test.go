@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonesrussell/loggo"
+	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/jonesrussell/page-prowler/internal/consumer"
+	"github.com/jonesrussell/page-prowler/internal/stats"
+	"github.com/jonesrussell/page-prowler/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newResultsTestManager(t *testing.T) *crawler.CrawlManager {
+	t.Helper()
+
+	logger := loggo.NewMockLoggerInterface(gomock.NewController(t))
+
+	published := time.Date(2024, 3, 5, 13, 30, 0, 0, time.UTC)
+	dbManager := dbmanager.NewMockDBManager()
+	dbManager.SavedResults = []models.PageData{
+		{URL: "https://example.com/b", MatchingTerms: []string{"police"}, Relevance: 0.4, SimilarityScore: 0.5},
+		{URL: "https://example.com/a", MatchingTerms: []string{"fire"}, Relevance: 0.8, SimilarityScore: 0.9, MatchLocations: []string{"url", "anchor"}, PageMetadata: models.PageMetadata{
+			Title:         "Fire downtown",
+			PublishedTime: &published,
+		}},
+		{URL: "https://example.com/c", MatchingTerms: []string{"police", "fire"}, Relevance: 0.6, SimilarityScore: 0.7},
+		{URL: "https://example.com/d", MatchingTerms: []string{"police"}, SimilarityScore: 0.6},
+	}
+	dbManager.SavedStats = &stats.Stats{TotalPages: 4, TotalLinks: 10, MatchedLinks: 4, NotMatchedLinks: 6}
+
+	return crawler.NewCrawlManager(logger, dbManager, nil, nil)
+}
+
+func TestRunResultsCmd(t *testing.T) {
+	tests := []struct {
+		name     string
+		sortBy   string
+		term     string
+		minScore float64
+		wantURLs []string
+	}{
+		{
+			name:     "sort by score",
+			sortBy:   consumer.SortScore,
+			wantURLs: []string{"https://example.com/a", "https://example.com/c", "https://example.com/b", "https://example.com/d"},
+		},
+		{
+			name:     "sort by url",
+			sortBy:   consumer.SortURL,
+			wantURLs: []string{"https://example.com/a", "https://example.com/b", "https://example.com/c", "https://example.com/d"},
+		},
+		{
+			name:     "filter by term",
+			sortBy:   consumer.SortScore,
+			term:     "Police",
+			wantURLs: []string{"https://example.com/c", "https://example.com/b", "https://example.com/d"},
+		},
+		{
+			name:     "minimum score",
+			sortBy:   consumer.SortScore,
+			minScore: 0.5,
+			wantURLs: []string{"https://example.com/a", "https://example.com/c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := runResultsCmd(context.Background(), &buf, newResultsTestManager(t), "site", tt.sortBy, tt.term, tt.minScore, OutputJSON)
+			require.NoError(t, err)
+
+			var got ResultsOutput
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+
+			var urls []string
+			for _, page := range got.Pages {
+				urls = append(urls, page.URL)
+				assert.Zero(t, page.SimilarityScore)
+			}
+			assert.Equal(t, tt.wantURLs, urls)
+			assert.Equal(t, 10, got.Stats.TotalLinks)
+		})
+	}
+}
+
+func TestRunResultsCmdFormats(t *testing.T) {
+	var buf bytes.Buffer
+	err := runResultsCmd(context.Background(), &buf, newResultsTestManager(t), "site", consumer.SortURL, "fire", 0, OutputCSV)
+	require.NoError(t, err)
+	assert.Equal(t, "url,matching_terms,relevance,match_locations,title,description,image,canonical_url,published_time\n"+
+		"https://example.com/a,fire,0.8,url;anchor,Fire downtown,,,,2024-03-05T13:30:00Z\n"+
+		"https://example.com/c,police;fire,0.6,,,,,,\n", buf.String())
+
+	buf.Reset()
+	err = runResultsCmd(context.Background(), &buf, newResultsTestManager(t), "site", consumer.SortScore, "", 0, OutputTable)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Not matched links:  6")
+	assert.Contains(t, buf.String(), "0.80   https://example.com/a  fire")
+	assert.Contains(t, buf.String(), "2024-03-05  Fire downtown")
+
+	err = runResultsCmd(context.Background(), &buf, newResultsTestManager(t), "site", consumer.SortScore, "", 0, "xml")
+	assert.Error(t, err)
+}
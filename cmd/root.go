@@ -34,10 +34,14 @@ func NewRootCmd(manager *crawler.CrawlManager, newsService news.Service) *cobra.
 	// Create a new crawl command with the manager
 	crawlCmd := NewCrawlCmd(manager)
 	resultsCmd := NewResultsCmd(manager)
-	// apiCmd := NewAPICmd(manager)
+	runsCmd := NewRunsCmd(manager)
+	apiCmd := NewAPICmd(manager)
 	workerCmd := NewWorkerCmd(manager)
 	getLinksCmd := NewGetLinksCmd(manager)
 	clearlinksCmd := NewClearlinksCmd(manager)
+	migrateCmd := NewMigrateCmd(manager)
+	queryCmd := NewQueryCmd(manager)
+	matchCmd := NewMatchCmd(manager)
 	genSiteCmd := NewGenSiteCmd(newsService) // Pass newsService to NewGenSiteCmd
 
 	serveCmd := NewServeCmd(newsService)
@@ -45,10 +49,14 @@ func NewRootCmd(manager *crawler.CrawlManager, newsService news.Service) *cobra.
 	// Add the commands to the root command
 	rootCmd.AddCommand(crawlCmd)
 	rootCmd.AddCommand(resultsCmd)
-	// rootCmd.AddCommand(apiCmd)
+	rootCmd.AddCommand(runsCmd)
+	rootCmd.AddCommand(apiCmd)
 	rootCmd.AddCommand(workerCmd)
 	rootCmd.AddCommand(getLinksCmd)
 	rootCmd.AddCommand(clearlinksCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(queryCmd)
+	rootCmd.AddCommand(matchCmd)
 	rootCmd.AddCommand(genSiteCmd)
 	rootCmd.AddCommand(serveCmd)
 
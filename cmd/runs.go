@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// NewRunsCmd creates a new runs command
+func NewRunsCmd(manager crawler.CrawlManagerInterface) *cobra.Command {
+	var siteid, output string
+
+	runsCmd := &cobra.Command{
+		Use:   "runs",
+		Short: "List the crawl runs for a given siteid",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if manager == nil {
+				return errors.New("manager is nil")
+			}
+
+			if siteid == "" {
+				siteid = viper.GetString("siteid")
+			}
+			if siteid == "" {
+				return ErrSiteidRequired
+			}
+
+			runs, err := manager.GetDBManager().ListCrawlRuns(cmd.Context(), siteid)
+			if err != nil {
+				return fmt.Errorf("failed to list crawl runs: %v", err)
+			}
+
+			if output == OutputJSON {
+				return writeIndentedJSON(cmd.OutOrStdout(), runs)
+			}
+			return writeCrawlRunsTable(cmd.OutOrStdout(), runs)
+		},
+	}
+
+	runsCmd.Flags().StringVarP(&siteid, "siteid", "s", "", "Site ID to list runs for")
+	runsCmd.Flags().StringVarP(&output, "output", "o", OutputTable, "Output format: \"table\" or \"json\"")
+
+	runsCmd.AddCommand(newRunsShowCmd(manager))
+
+	return runsCmd
+}
+
+func newRunsShowCmd(manager crawler.CrawlManagerInterface) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <run-id>",
+		Short: "Show the details of a crawl run",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if manager == nil {
+				return errors.New("manager is nil")
+			}
+
+			run, err := manager.GetDBManager().GetCrawlRun(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get crawl run: %w", err)
+			}
+
+			return writeIndentedJSON(cmd.OutOrStdout(), run)
+		},
+	}
+}
+
+func writeCrawlRunsTable(w io.Writer, runs []models.CrawlRun) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ID\tSTATUS\tSTARTED\tDURATION\tPAGES\tLINKS\tMATCHED\tERROR")
+	for _, run := range runs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%s\n",
+			run.ID,
+			run.Status,
+			run.StartedAt.Local().Format(time.DateTime),
+			run.Duration().Round(time.Second),
+			run.TotalPages,
+			run.TotalLinks,
+			run.MatchedLinks,
+			run.Error,
+		)
+	}
+
+	return tw.Flush()
+}
@@ -2,12 +2,36 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
 
+	"github.com/hibiken/asynq"
+	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/dbmanager"
 	"github.com/spf13/cobra"
 )
 
 import "fmt"
 
+// ErrRedisRequired is returned by the commands that use the task queue when
+// the redis storage backend is not configured.
+var ErrRedisRequired = errors.New("this command requires the redis storage backend (DB_BACKEND=redis)")
+
+// asynqRedisOpt returns the options to connect Asynq to the Redis server that
+// the manager stores its data in.
+func asynqRedisOpt(manager crawler.CrawlManagerInterface) (asynq.RedisClientOpt, error) {
+	backend, ok := manager.GetDBManager().(dbmanager.RedisBackend)
+	if !ok {
+		return asynq.RedisClientOpt{}, ErrRedisRequired
+	}
+
+	redisOptions := backend.RedisOptions()
+	return asynq.RedisClientOpt{
+		Addr:     redisOptions.Addr,
+		Password: redisOptions.Password,
+		DB:       redisOptions.DB,
+	}, nil
+}
+
 // ExecuteCommand is a helper function intended for use in tests.
 // It executes a Cobra command with the provided arguments and returns the captured output and error.
 func ExecuteCommand(root *cobra.Command, args ...string) (output string, err error) {
@@ -12,10 +12,16 @@ func NewWorkerCmd(manager crawler.CrawlManagerInterface) *cobra.Command {
 	workerCmd := &cobra.Command{
 		Use:   "worker",
 		Short: "Start the Asynq worker",
-		Run: func(_ *cobra.Command, _ []string) {
+		RunE: func(_ *cobra.Command, _ []string) error {
+			redisConnOpt, err := asynqRedisOpt(manager)
+			if err != nil {
+				return err
+			}
+
 			concurrency := 10 // Replace with the concurrency level you want
 			debug := viper.GetBool("debug")
-			worker.StartWorker(concurrency, manager, debug)
+			worker.StartWorker(concurrency, manager, redisConnOpt, debug)
+			return nil
 		},
 	}
 
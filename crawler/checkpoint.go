@@ -0,0 +1,237 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gocolly/colly"
+	"github.com/gocolly/colly/queue"
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/jonesrussell/page-prowler/models"
+)
+
+// checkpointInterval is the number of pages crawled between two checkpoints
+// of a crawl run.
+var checkpointInterval int64 = 20
+
+// depthKey is the context key of the depth a queued page was found at. colly
+// does not keep the depth of the requests it queues, they all start at 0.
+const depthKey = "prowl.depth"
+
+// ErrRunNotResumable is returned when resuming a crawl run that completed or
+// was never checkpointed.
+var ErrRunNotResumable = errors.New("crawl run cannot be resumed")
+
+// ResumeCrawl resumes an interrupted crawl run from its last checkpoint, with
+// the options the run started with. The run keeps its ID, and its statistics
+// carry on from the checkpoint.
+func (cm *CrawlManager) ResumeCrawl(ctx context.Context, runID string) error {
+	run, err := cm.DBManager.GetCrawlRun(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("failed to get crawl run: %w", err)
+	}
+	if run.Status == models.CrawlRunStatusCompleted {
+		return fmt.Errorf("%w: run %s completed", ErrRunNotResumable, runID)
+	}
+
+	checkpoint, err := cm.DBManager.GetCheckpoint(ctx, runID)
+	if errors.Is(err, dbmanager.ErrCheckpointNotFound) {
+		return fmt.Errorf("%w: run %s has no checkpoint", ErrRunNotResumable, runID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get checkpoint: %v", err)
+	}
+
+	options := &CrawlOptions{}
+	if err := json.Unmarshal(run.Options, options); err != nil {
+		return fmt.Errorf("failed to read the options of crawl run %s: %v", runID, err)
+	}
+
+	run.Status = models.CrawlRunStatusRunning
+	run.EndedAt = nil
+	run.Error = ""
+
+	cm.Logger.Info("[Crawl] Resuming crawl run", "id", run.ID, "queued", len(checkpoint.Queue))
+
+	return cm.crawl(ctx, run, options, checkpoint)
+}
+
+// enqueue adds a page found at depth to the queue of the crawl.
+func (s *crawlSession) enqueue(q *queue.Queue, rawURL string, depth int) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	ctx := colly.NewContext()
+	if depth > 0 {
+		ctx.Put(depthKey, strconv.Itoa(depth))
+	}
+
+	s.mu.Lock()
+	s.frontier[u.String()] = depth
+	s.mu.Unlock()
+
+	return q.AddRequest(&colly.Request{URL: u, Method: "GET", Ctx: ctx})
+}
+
+// depth returns the depth of a request from the start URLs. The pages a
+// resumed crawl queues again carry the depth they were found at in their
+// context, which the pages they link to share.
+func (s *crawlSession) depth(r *colly.Request) int {
+	offset, _ := strconv.Atoi(r.Ctx.Get(depthKey))
+	return r.Depth + offset
+}
+
+// trackRequest records a request the crawl is about to make, which stays on
+// the queue of the checkpoints until it is done.
+func (s *crawlSession) trackRequest(r *colly.Request) {
+	pageURL := r.URL.String()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.frontier[pageURL] = s.depth(r)
+	s.started[r.ID] = pageURL
+}
+
+// finishRequest records that a request is done. r.URL is the URL redirects
+// led to, the request is known by its ID.
+func (s *crawlSession) finishRequest(r *colly.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pageURL, ok := s.started[r.ID]
+	if !ok {
+		return
+	}
+	delete(s.started, r.ID)
+	delete(s.frontier, pageURL)
+	s.crawled[pageURL] = true
+}
+
+// checkpoint returns the progress of the crawl.
+func (s *crawlSession) checkpoint() *models.CrawlCheckpoint {
+	linkStats := s.stats.LinkStats
+	checkpoint := &models.CrawlCheckpoint{
+		RunID:           s.run.ID,
+		TotalPages:      linkStats.GetTotalPages(),
+		TotalLinks:      linkStats.GetTotalLinks(),
+		MatchedLinks:    linkStats.GetMatchedLinks(),
+		NotMatchedLinks: linkStats.GetNotMatchedLinks(),
+		SavedAt:         time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Pages queued more than once are crawled once
+	for pageURL, depth := range s.frontier {
+		if !s.crawled[pageURL] {
+			checkpoint.Queue = append(checkpoint.Queue, models.QueuedPage{URL: pageURL, Depth: depth})
+		}
+	}
+	for pageURL := range s.crawled {
+		checkpoint.Visited = append(checkpoint.Visited, pageURL)
+	}
+	for pageURL := range s.pending {
+		checkpoint.Pending = append(checkpoint.Pending, pageURL)
+	}
+
+	// Shallow pages first, as the crawl would have reached them
+	sort.Slice(checkpoint.Queue, func(i, j int) bool {
+		a, b := checkpoint.Queue[i], checkpoint.Queue[j]
+		if a.Depth != b.Depth {
+			return a.Depth < b.Depth
+		}
+		return a.URL < b.URL
+	})
+	sort.Strings(checkpoint.Visited)
+	sort.Strings(checkpoint.Pending)
+
+	return checkpoint
+}
+
+// saveCheckpoint saves the progress of the crawl, once its queue is filled.
+func (s *crawlSession) saveCheckpoint() {
+	if !s.queued.Load() {
+		return
+	}
+
+	s.checkpointMu.Lock()
+	defer s.checkpointMu.Unlock()
+
+	checkpoint := s.checkpoint()
+	if err := s.manager.DBManager.SaveCheckpoint(context.Background(), checkpoint); err != nil {
+		s.manager.Logger.Error("Error saving checkpoint", err)
+		return
+	}
+	s.manager.Logger.Debug("[saveCheckpoint]", "queued", len(checkpoint.Queue))
+}
+
+// pageCrawled counts a page the crawl is done with, saving a checkpoint every
+// checkpointInterval pages.
+func (s *crawlSession) pageCrawled() {
+	if s.crawledPages.Add(1)%checkpointInterval == 0 {
+		s.saveCheckpoint()
+	}
+}
+
+// finishCheckpoint deletes the checkpoint of a crawl that completed, and
+// saves the last one of a crawl that did not, for it to be resumed.
+func (s *crawlSession) finishCheckpoint(crawlErr error) {
+	if crawlErr != nil {
+		s.saveCheckpoint()
+		return
+	}
+	if err := s.manager.DBManager.DeleteCheckpoint(context.Background(), s.run.ID); err != nil {
+		s.manager.Logger.Error("Error deleting checkpoint", err)
+	}
+}
+
+// restore carries on the crawl from a checkpoint: its statistics, the matched
+// pages whose metadata is still to fetch, and the pages it crawled, which it
+// does not crawl again.
+func (s *crawlSession) restore(checkpoint *models.CrawlCheckpoint) error {
+	s.resumed = checkpoint
+
+	linkStats := s.stats.LinkStats
+	linkStats.TotalPages = checkpoint.TotalPages
+	linkStats.TotalLinks = checkpoint.TotalLinks
+	linkStats.MatchedLinks = checkpoint.MatchedLinks
+	linkStats.NotMatchedLinks = checkpoint.NotMatchedLinks
+
+	// Drop what the interrupted crawl left in its storage, the pages it was
+	// crawling are queued again
+	if err := s.storage.Clear(); err != nil {
+		return fmt.Errorf("failed to clear crawl storage: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pageURL := range checkpoint.Pending {
+		s.pending[pageURL] = true
+	}
+	for _, pageURL := range checkpoint.Visited {
+		s.crawled[pageURL] = true
+		if err := s.storage.Visited(requestHash(pageURL)); err != nil {
+			return fmt.Errorf("failed to restore visited pages: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// requestHash returns the key colly stores a visited URL under.
+func requestHash(rawURL string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(rawURL))
+	return h.Sum64()
+}
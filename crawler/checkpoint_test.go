@@ -0,0 +1,141 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/jonesrussell/page-prowler/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumeCrawl(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	hits := make(map[string]int)
+
+	// The home page links to ten pages, each linking to a page of its own
+	// and that one to a page beyond MaxDepth. The crawl is interrupted while
+	// fetching the fourth page.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", http.NotFound)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+
+		if r.URL.Path == "/page-3" {
+			cancel()
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><body>")
+		if r.URL.Path == "/" {
+			for i := 0; i < 10; i++ {
+				fmt.Fprintf(w, `<a href="/page-%d">Page %d</a>`, i, i)
+			}
+		} else {
+			fmt.Fprintf(w, `<a href="%s/more">More</a>`, r.URL.Path)
+		}
+		fmt.Fprint(w, "</body></html>")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cm, dbManager := newTestCrawlManager(t)
+
+	err := cm.CrawlWithOptions(ctx, &CrawlOptions{
+		CrawlSiteID:           "site",
+		StartURL:              server.URL + "/",
+		SearchTerms:           []string{"flood"},
+		MaxDepth:              2,
+		MaxConcurrentRequests: 1,
+		DelayBetweenRequests:  time.Millisecond,
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	runs, err := dbManager.ListCrawlRuns(context.Background(), "site")
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	run := runs[0]
+	assert.Equal(t, models.CrawlRunStatusCancelled, run.Status)
+
+	checkpoint, err := dbManager.GetCheckpoint(context.Background(), run.ID)
+	require.NoError(t, err)
+	assert.Contains(t, checkpoint.Visited, server.URL+"/")
+	assert.Contains(t, checkpoint.Queue, models.QueuedPage{URL: server.URL + "/page-9", Depth: 1})
+
+	require.NoError(t, cm.ResumeCrawl(context.Background(), run.ID))
+
+	// The pages crawled before the interruption are not crawled again, the
+	// page being fetched is, and the depth of resumed pages is kept
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, hits["/"])
+	for i := 0; i < 10; i++ {
+		page := fmt.Sprintf("/page-%d", i)
+		if i != 3 {
+			assert.Equal(t, 1, hits[page], page)
+		}
+		assert.Equal(t, 1, hits[page+"/more"], page+"/more")
+		assert.Zero(t, hits[page+"/more/more"], page+"/more/more")
+	}
+
+	resumed, err := dbManager.GetCrawlRun(context.Background(), run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CrawlRunStatusCompleted, resumed.Status)
+	assert.Empty(t, resumed.Error)
+	assert.Equal(t, 21, resumed.TotalPages)
+
+	_, err = dbManager.GetCheckpoint(context.Background(), run.ID)
+	assert.ErrorIs(t, err, dbmanager.ErrCheckpointNotFound)
+
+	err = cm.ResumeCrawl(context.Background(), run.ID)
+	assert.ErrorIs(t, err, ErrRunNotResumable)
+}
+
+func TestCrawlCheckpoints(t *testing.T) {
+	interval := checkpointInterval
+	checkpointInterval = 2
+	defer func() { checkpointInterval = interval }()
+
+	server := newSiteServer()
+	defer server.Close()
+
+	cm, dbManager := newTestCrawlManager(t)
+	session, err := cm.newCrawlSession(context.Background(), newCrawlRun(&CrawlOptions{}), &CrawlOptions{})
+	require.NoError(t, err)
+
+	// Nothing is saved before the queue is filled
+	session.pageCrawled()
+	session.pageCrawled()
+	_, err = dbManager.GetCheckpoint(context.Background(), session.run.ID)
+	assert.ErrorIs(t, err, dbmanager.ErrCheckpointNotFound)
+
+	session.queued.Store(true)
+	session.frontier[server.URL+"/news/sports"] = 1
+	session.frontier[server.URL+"/"] = 0
+	session.crawled[server.URL+"/"] = true
+	session.pending[server.URL+"/news/flood-warning-downtown"] = true
+	session.stats.LinkStats.IncrementTotalPages()
+
+	session.pageCrawled()
+	_, err = dbManager.GetCheckpoint(context.Background(), session.run.ID)
+	assert.ErrorIs(t, err, dbmanager.ErrCheckpointNotFound)
+
+	session.pageCrawled()
+	checkpoint, err := dbManager.GetCheckpoint(context.Background(), session.run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []models.QueuedPage{{URL: server.URL + "/news/sports", Depth: 1}}, checkpoint.Queue)
+	assert.Equal(t, []string{server.URL + "/"}, checkpoint.Visited)
+	assert.Equal(t, []string{server.URL + "/news/flood-warning-downtown"}, checkpoint.Pending)
+	assert.Equal(t, 1, checkpoint.TotalPages)
+}
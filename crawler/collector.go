@@ -21,6 +21,7 @@ type CollectorInterface interface {
 // CollectorWrapper is a wrapper around to colly.Collector that implements the CollectorInterface.
 type CollectorWrapper struct {
 	collector            *colly.Collector
+	transport            http.RoundTripper // the transport of the collector, which colly does not expose
 	Logger               loggo.LoggerInterface
 	DisallowedURLFilters []*regexp.Regexp
 }
@@ -29,11 +30,12 @@ var _ CollectorInterface = &CollectorWrapper{}
 
 func NewCollectorWrapper(collector *colly.Collector, logger loggo.LoggerInterface, disallowedURLFilters []*regexp.Regexp) *CollectorWrapper { // Add disallowedURLFilters as a parameter
 	// Set a timeout
-	collector.WithTransport(&http.Transport{
+	transport := &http.Transport{
 		DialContext: (&net.Dialer{
 			Timeout: 5 * time.Second, // Set the timeout
 		}).DialContext,
-	})
+	}
+	collector.WithTransport(transport)
 
 	// Add OnResponse callback
 	collector.OnResponse(func(r *colly.Response) {
@@ -47,6 +49,7 @@ func NewCollectorWrapper(collector *colly.Collector, logger loggo.LoggerInterfac
 
 	wrapper := &CollectorWrapper{
 		collector:            collector,
+		transport:            transport,
 		Logger:               logger,
 		DisallowedURLFilters: disallowedURLFilters,
 	}
@@ -61,6 +64,20 @@ func (cw *CollectorWrapper) GetCollector() *colly.Collector {
 	return cw.collector
 }
 
+// Transport returns the transport the collector makes its requests with.
+func (cw *CollectorWrapper) Transport() http.RoundTripper {
+	if cw.transport == nil {
+		return http.DefaultTransport
+	}
+	return cw.transport
+}
+
+// WithTransport makes the collector use transport for its requests.
+func (cw *CollectorWrapper) WithTransport(transport http.RoundTripper) {
+	cw.collector.WithTransport(transport)
+	cw.transport = transport
+}
+
 // Visit method with logging and timing
 func (cw *CollectorWrapper) Visit(URL string) error {
 	// Check if the URL matches any of the disallowed URL filters
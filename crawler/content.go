@@ -0,0 +1,84 @@
+package crawler
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// boilerplateSelector matches the parts of a page around its content, such as
+// navigation, scripts and page chrome.
+const boilerplateSelector = `script, style, noscript, template, iframe, svg, form, nav, header, footer, aside,
+	[role="navigation"], [role="banner"], [role="contentinfo"], [role="complementary"], [aria-hidden="true"]`
+
+// contentSelectors match the elements that hold the content of a page, most
+// specific first.
+var contentSelectors = []string{
+	`[itemprop="articleBody"]`,
+	"article",
+	"main",
+	`[role="main"]`,
+}
+
+// extractContent returns the readable text of the main content of a page,
+// without its boilerplate.
+func extractContent(page *goquery.Selection) string {
+	body := page.Find("body").Clone()
+	body.Find(boilerplateSelector).Remove()
+
+	return strings.Join(strings.Fields(text(mainContent(body))), " ")
+}
+
+// text returns the text of selection with its text nodes separated by
+// spaces, so that the words of adjacent blocks are not run together.
+func text(selection *goquery.Selection) string {
+	var parts []string
+	selection.Contents().Each(func(_ int, node *goquery.Selection) {
+		switch goquery.NodeName(node) {
+		case "#text":
+			parts = append(parts, node.Text())
+		case "#comment":
+		default:
+			parts = append(parts, text(node))
+		}
+	})
+	return strings.Join(parts, " ")
+}
+
+// mainContent returns the element of body that holds the content. Without
+// markup saying which one it is, it is the element with the most paragraph
+// text.
+func mainContent(body *goquery.Selection) *goquery.Selection {
+	for _, selector := range contentSelectors {
+		if content := longest(body.Find(selector)); content != nil {
+			return content
+		}
+	}
+
+	var content *goquery.Selection
+	var contentLength int
+	body.Find("p").Each(func(_ int, p *goquery.Selection) {
+		parent := p.Parent()
+		if length := len(strings.TrimSpace(parent.ChildrenFiltered("p").Text())); length > contentLength {
+			content, contentLength = parent, length
+		}
+	})
+	if content == nil {
+		return body
+	}
+
+	return content
+}
+
+// longest returns the element of selection with the most text, or nil if it
+// has no text.
+func longest(selection *goquery.Selection) *goquery.Selection {
+	var result *goquery.Selection
+	var resultLength int
+	selection.Each(func(_ int, s *goquery.Selection) {
+		if length := len(strings.TrimSpace(s.Text())); length > resultLength {
+			result, resultLength = s, length
+		}
+	})
+	return result
+}
@@ -0,0 +1,137 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/jonesrussell/page-prowler/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractContent(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "article element",
+			html: `<html><body>
+				<nav><a href="/">Home</a> <a href="/news">News</a></nav>
+				<article><h1>Flood warning</h1><p>Residents are asked to avoid the river.</p></article>
+				<aside>Most read</aside>
+				<footer>Copyright</footer>
+			</body></html>`,
+			want: "Flood warning Residents are asked to avoid the river.",
+		},
+		{
+			name: "most paragraph text",
+			html: `<html><body>
+				<div class="menu"><p>Sign in</p></div>
+				<div class="story"><p>Residents are asked to avoid the river.</p><p>Crews are on site.</p></div>
+				<script>var flood = true;</script>
+			</body></html>`,
+			want: "Residents are asked to avoid the river. Crews are on site.",
+		},
+		{
+			name: "no paragraphs",
+			html: `<html><body><header>Site</header><div>Residents are asked to avoid the river.</div></body></html>`,
+			want: "Residents are asked to avoid the river.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, extractContent(doc.Selection))
+
+			// The page itself is left as it was
+			assert.Equal(t, 1, doc.Find("body > footer, body > header, body > script").Length())
+		})
+	}
+}
+
+// newOpaqueSlugServer serves a home page linking to an article whose URL
+// and anchor text do not mention the term, only its content does.
+func newOpaqueSlugServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", http.NotFound)
+	mux.HandleFunc("/news/1.7051216", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Evacuation ordered downtown</title></head><body>
+			<nav><a href="/">Home</a></nav>
+			<article><p>The river rose overnight and flood water reached the main street.</p></article>
+		</body></html>`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body><a href="/news/1.7051216">Evacuation ordered downtown</a></body></html>`)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestCrawlMatchContent(t *testing.T) {
+	tests := []struct {
+		name         string
+		matchContent bool
+		wantMatch    bool
+	}{
+		{name: "links only", matchContent: false, wantMatch: false},
+		{name: "content", matchContent: true, wantMatch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newOpaqueSlugServer()
+			defer server.Close()
+
+			cm, dbManager := newTestCrawlManager(t)
+			require.NoError(t, cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+				CrawlSiteID:          "site",
+				StartURL:             server.URL,
+				SearchTerms:          []string{"flood"},
+				MaxDepth:             2,
+				MatchContent:         tt.matchContent,
+				DelayBetweenRequests: time.Millisecond,
+			}))
+
+			result, err := dbManager.GetResult(context.Background(), "site", server.URL+"/news/1.7051216")
+			if !tt.wantMatch {
+				assert.ErrorIs(t, err, dbmanager.ErrResultNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{"flood"}, result.MatchingTerms)
+			assert.Equal(t, []string{models.MatchLocationBody}, result.MatchLocations)
+			assert.Equal(t, "Evacuation ordered downtown", result.Title)
+		})
+	}
+}
+
+func TestCrawlRecordsLinkMatchLocations(t *testing.T) {
+	server := newSiteServer()
+	defer server.Close()
+
+	cm, dbManager := newTestCrawlManager(t)
+	require.NoError(t, cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+		CrawlSiteID:          "site",
+		StartURL:             server.URL,
+		SearchTerms:          []string{"flood"},
+		MaxDepth:             1,
+		DelayBetweenRequests: time.Millisecond,
+	}))
+
+	result, err := dbManager.GetResult(context.Background(), "site", server.URL+"/news/flood-warning-downtown")
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.MatchLocationURL, models.MatchLocationAnchor}, result.MatchLocations)
+}
@@ -1,11 +1,9 @@
 package crawler
 
 import (
-	"sync"
 	"time"
 
 	"github.com/jonesrussell/loggo"
-	"github.com/jonesrussell/page-prowler/internal/stats"
 )
 
 const (
@@ -16,12 +14,3 @@ const (
 func (cm *CrawlManager) GetLogger() loggo.LoggerInterface {
 	return cm.Logger
 }
-
-func (cm *CrawlManager) initializeStatsManager() {
-	cm.StatsManager = &StatsManager{
-		LinkStats:   &stats.Stats{},
-		LinkStatsMu: sync.RWMutex{},
-	}
-	cm.CrawlingMu.Lock()
-	defer cm.CrawlingMu.Unlock()
-}
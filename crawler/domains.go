@@ -0,0 +1,138 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrNoStartURL is returned for crawls without a start URL.
+var ErrNoStartURL = errors.New("no start URL")
+
+// Seeds returns the URLs the crawl starts from, StartURL and StartURLs,
+// without duplicates.
+func (o *CrawlOptions) Seeds() []string {
+	var seeds []string
+	seen := make(map[string]bool)
+	for _, seed := range append([]string{o.StartURL}, o.StartURLs...) {
+		if seed = strings.TrimSpace(seed); seed != "" && !seen[seed] {
+			seen[seed] = true
+			seeds = append(seeds, seed)
+		}
+	}
+	return seeds
+}
+
+// ValidateAllowedDomain checks a domain pattern of AllowedDomains: a host
+// name, optionally with a port, such as "cp24.com" or "localhost:8080", or a
+// wildcard, such as "*.cp24.com", which matches cp24.com and its subdomains.
+func ValidateAllowedDomain(pattern string) error {
+	host := strings.TrimPrefix(pattern, "*.")
+	if host == "" || strings.ContainsAny(host, "*/?#@ ") {
+		return fmt.Errorf("invalid allowed domain %q: expected a host such as \"example.com\" or \"*.example.com\"", pattern)
+	}
+	if u, err := url.Parse("http://" + host); err != nil || u.Host != host || u.Hostname() == "" {
+		return fmt.Errorf("invalid allowed domain %q", pattern)
+	}
+	return nil
+}
+
+// ValidateAllowedDomains checks each of the given domain patterns.
+func ValidateAllowedDomains(patterns []string) error {
+	for _, pattern := range patterns {
+		if err := ValidateAllowedDomain(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allowedDomains returns the domain patterns the crawl may visit: the hosts
+// of its seeds and its AllowedDomains.
+func (o *CrawlOptions) allowedDomains() ([]string, error) {
+	if err := ValidateAllowedDomains(o.AllowedDomains); err != nil {
+		return nil, err
+	}
+
+	seeds := o.Seeds()
+	if len(seeds) == 0 {
+		return nil, ErrNoStartURL
+	}
+
+	domains := make([]string, 0, len(seeds)+len(o.AllowedDomains))
+	for _, seed := range seeds {
+		host, err := allowedHost(seed)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, host)
+	}
+	for _, pattern := range o.AllowedDomains {
+		domains = append(domains, strings.ToLower(pattern))
+	}
+
+	return domains, nil
+}
+
+// domainAllowed reports whether host, with or without a port, matches one
+// of the domain patterns. Patterns without a port match any port.
+func domainAllowed(patterns []string, host string) bool {
+	host = strings.ToLower(host)
+	hostname := host
+	if u, err := url.Parse("http://" + host); err == nil {
+		hostname = u.Hostname()
+	}
+
+	for _, pattern := range patterns {
+		target := hostname
+		if strings.Contains(strings.TrimPrefix(pattern, "*."), ":") {
+			target = host
+		}
+
+		if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+			if target == domain || strings.HasSuffix(target, "."+domain) {
+				return true
+			}
+			continue
+		}
+		if target == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// allowed reports whether the crawl may visit rawURL.
+func (s *crawlSession) allowed(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return domainAllowed(s.domains, u.Host)
+}
+
+// checkRedirect refuses redirects to the domains the crawl may not visit.
+// colly only checks redirects against its exact AllowedDomains.
+func (s *crawlSession) checkRedirect(req *http.Request, via []*http.Request) error {
+	if !domainAllowed(s.domains, req.URL.Host) {
+		return fmt.Errorf("not following redirect to %s: domain not allowed", req.URL.Host)
+	}
+
+	// As colly does, honour the default limit of net/http and keep the
+	// headers of the previous request
+	if len(via) >= 10 {
+		return http.ErrUseLastResponse
+	}
+	last := via[len(via)-1]
+	for name, values := range last.Header {
+		for _, value := range values {
+			req.Header.Set(name, value)
+		}
+	}
+	if req.URL.Host != last.URL.Host {
+		req.Header.Del("Authorization")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDomainAllowed(t *testing.T) {
+	patterns := []string{"cp24.com", "*.bell.ca", "localhost:8080"}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "cp24.com", want: true},
+		{host: "CP24.com:443", want: true},
+		{host: "www.cp24.com", want: false},
+		{host: "bell.ca", want: true},
+		{host: "www.bell.ca", want: true},
+		{host: "news.www.bell.ca", want: true},
+		{host: "notbell.ca", want: false},
+		{host: "localhost:8080", want: true},
+		{host: "localhost:9090", want: false},
+		{host: "ctvnews.ca", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			assert.Equal(t, tt.want, domainAllowed(patterns, tt.host))
+		})
+	}
+}
+
+func TestValidateAllowedDomain(t *testing.T) {
+	for _, pattern := range []string{"cp24.com", "*.cp24.com", "localhost:8080", "127.0.0.1"} {
+		assert.NoError(t, ValidateAllowedDomain(pattern), pattern)
+	}
+	for _, pattern := range []string{"", "*.", "*cp24.com", "news.*.com", "https://cp24.com", "cp24.com/news"} {
+		assert.Error(t, ValidateAllowedDomain(pattern), pattern)
+	}
+}
+
+func TestSeeds(t *testing.T) {
+	options := &CrawlOptions{
+		StartURL:  "https://www.cp24.com",
+		StartURLs: []string{"https://cp24.com/news", " ", "https://www.cp24.com"},
+	}
+	assert.Equal(t, []string{"https://www.cp24.com", "https://cp24.com/news"}, options.Seeds())
+
+	_, err := (&CrawlOptions{}).allowedDomains()
+	assert.ErrorIs(t, err, ErrNoStartURL)
+}
+
+// newSisterServers serves two sites on different ports of the same host. The
+// home page of the first links to an article of the second, which links to
+// another.
+func newSisterServers() (*httptest.Server, *httptest.Server) {
+	sister := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/robots.txt":
+			http.NotFound(w, r)
+		case "/flood-warning":
+			fmt.Fprint(w, `<html><body><a href="/flood-maps">Flood maps</a></body></html>`)
+		default:
+			fmt.Fprint(w, `<html><body><a href="/flood-warning">Flood warning</a></body></html>`)
+		}
+	}))
+
+	main := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `<html><body><a href="%s/flood-warning">Flood warning</a></body></html>`, sister.URL)
+	}))
+
+	return main, sister
+}
+
+func TestCrawlWithOptionsAllowedDomains(t *testing.T) {
+	main, sister := newSisterServers()
+	defer main.Close()
+	defer sister.Close()
+
+	tests := []struct {
+		name    string
+		options CrawlOptions
+		want    []string
+	}{
+		{
+			name:    "host of the start URL only",
+			options: CrawlOptions{StartURL: main.URL},
+			want:    []string{"/flood-warning"},
+		},
+		{
+			name:    "allowed domain",
+			options: CrawlOptions{StartURL: main.URL, AllowedDomains: []string{strings.TrimPrefix(sister.URL, "http://")}},
+			want:    []string{"/flood-maps", "/flood-warning"},
+		},
+		{
+			name:    "several start URLs",
+			options: CrawlOptions{StartURL: main.URL, StartURLs: []string{sister.URL}},
+			want:    []string{"/flood-maps", "/flood-warning"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm, dbManager := newTestCrawlManager(t)
+
+			options := tt.options
+			options.CrawlSiteID = "site"
+			options.SearchTerms = []string{"flood"}
+			options.MaxDepth = 3
+			options.DelayBetweenRequests = time.Millisecond
+			require.NoError(t, cm.CrawlWithOptions(context.Background(), &options))
+
+			seen := make(map[string]bool)
+			for _, result := range dbManager.SavedResults {
+				seen[strings.TrimPrefix(result.URL, sister.URL)] = true
+			}
+			var got []string
+			for path := range seen {
+				got = append(got, path)
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
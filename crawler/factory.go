@@ -0,0 +1,94 @@
+package crawler
+
+import (
+	"github.com/gocolly/colly"
+	"github.com/gocolly/colly/queue"
+	"github.com/gocolly/colly/storage"
+	"github.com/gocolly/redisstorage"
+	"github.com/jonesrussell/loggo"
+)
+
+// CrawlStorage stores the visited URLs, cookies and request queue of a crawl.
+type CrawlStorage interface {
+	storage.Storage
+	queue.Storage
+	// Clear removes everything the crawl stored.
+	Clear() error
+	// Close releases the connections held by the storage.
+	Close() error
+}
+
+// CollectorFactory creates the collector and storage for a single crawl run.
+// Every crawl gets its own so that concurrent and sequential crawls never
+// share callbacks, visited URLs or connections.
+type CollectorFactory func(runID string, options *CrawlOptions) (*CollectorWrapper, CrawlStorage, error)
+
+// DefaultCollectorFactory returns a CollectorFactory that creates plain
+// collectors backed by in-memory storage.
+func DefaultCollectorFactory(logger loggo.LoggerInterface) CollectorFactory {
+	return func(_ string, _ *CrawlOptions) (*CollectorWrapper, CrawlStorage, error) {
+		collector := NewCollectorWrapper(colly.NewCollector(), logger, nil)
+		return collector, NewInMemoryStorage(), nil
+	}
+}
+
+// InMemoryStorage is a CrawlStorage that keeps everything in memory.
+type InMemoryStorage struct {
+	*storage.InMemoryStorage
+	*queue.InMemoryQueueStorage
+}
+
+var _ CrawlStorage = &InMemoryStorage{}
+
+// NewInMemoryStorage creates a new InMemoryStorage.
+func NewInMemoryStorage() *InMemoryStorage {
+	return &InMemoryStorage{
+		InMemoryStorage:      &storage.InMemoryStorage{},
+		InMemoryQueueStorage: &queue.InMemoryQueueStorage{MaxSize: 100000},
+	}
+}
+
+// Init initializes both the visited and the queue storage.
+func (s *InMemoryStorage) Init() error {
+	if err := s.InMemoryStorage.Init(); err != nil {
+		return err
+	}
+	return s.InMemoryQueueStorage.Init()
+}
+
+// Clear is a no-op, the storage is discarded with the crawl.
+func (s *InMemoryStorage) Clear() error {
+	return nil
+}
+
+// Close implements CrawlStorage.
+func (s *InMemoryStorage) Close() error {
+	return s.InMemoryStorage.Close()
+}
+
+// RedisStorage is a CrawlStorage backed by Redis.
+type RedisStorage struct {
+	*redisstorage.Storage
+}
+
+var _ CrawlStorage = &RedisStorage{}
+
+// NewRedisStorage creates a RedisStorage whose keys are all under prefix.
+func NewRedisStorage(addr, password string, db int, prefix string) *RedisStorage {
+	return &RedisStorage{
+		Storage: &redisstorage.Storage{
+			Address:  addr,
+			Password: password,
+			DB:       db,
+			Prefix:   prefix,
+		},
+	}
+}
+
+// Close closes the Redis client.
+func (s *RedisStorage) Close() error {
+	if s.Client == nil {
+		return nil
+	}
+	return s.Client.Close()
+}
@@ -0,0 +1,207 @@
+package crawler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly"
+	"github.com/gocolly/colly/queue"
+	"github.com/jonesrussell/page-prowler/internal/feed"
+	"github.com/jonesrussell/page-prowler/internal/language"
+)
+
+// maxFeeds bounds the sitemaps and feeds a crawl fetches, as the sitemap
+// indexes of large sites list thousands.
+const maxFeeds = 100
+
+// feedLinkSelector matches the links of a page to its RSS and Atom feeds.
+const feedLinkSelector = `link[rel="alternate"][type="application/rss+xml"], link[rel="alternate"][type="application/atom+xml"]`
+
+// errOutOfBudget is returned for the requests dropped because of MaxPages.
+var errOutOfBudget = errors.New("crawl budget reached")
+
+// seedFromFeeds fetches the sitemaps and feeds of the crawl, matches the
+// pages they list as links, with their title as anchor text, and adds them
+// to q. Only the Feeds of the crawl may be on domains it does not crawl.
+func (s *crawlSession) seedFromFeeds(q *queue.Queue, seeds []string) {
+	logger := s.manager.Logger
+
+	explicit := make(map[string]bool)
+	for _, source := range s.options.Feeds {
+		explicit[source] = true
+	}
+
+	sources := s.feedSources(seeds)
+	fetched := make(map[string]bool)
+	queued := make(map[string]bool)
+	matched := make(map[string]bool)
+
+	for len(sources) > 0 && len(fetched) < maxFeeds {
+		source := sources[0]
+		sources = sources[1:]
+		if fetched[source] {
+			continue
+		}
+		if !explicit[source] && !s.allowed(source) {
+			logger.Debug("[seedFromFeeds] Skipping feed of a domain not allowed", "feed", source)
+			continue
+		}
+		fetched[source] = true
+
+		data, err := s.fetch(source)
+		if err != nil {
+			if errors.Is(err, errOutOfBudget) || s.ctx.Err() != nil {
+				return
+			}
+			logger.Error("Error fetching feed", err)
+			continue
+		}
+
+		doc, err := feed.Parse(data)
+		if err != nil {
+			logger.Error("Error parsing feed "+source, err)
+			continue
+		}
+		logger.Debug("[seedFromFeeds]", "feed", source, "entries", len(doc.Entries), "sitemaps", len(doc.Sitemaps))
+
+		// Sitemap indexes list further sitemaps
+		sources = append(sources, doc.Sitemaps...)
+
+		// Pages listed by several sitemaps or feeds are matched with each
+		// title until one matches, and crawled once. Those an earlier crawl
+		// saw are crawled, not matched again.
+		for _, entry := range doc.Entries {
+			if matched[entry.URL] || !s.allowed(entry.URL) || !s.rules.allows(entry.URL) {
+				continue
+			}
+			if !s.known(entry.URL) {
+				matched[entry.URL] = s.handleFeedEntry(entry, source)
+			}
+
+			if queued[entry.URL] {
+				continue
+			}
+			queued[entry.URL] = true
+			if err := s.enqueue(q, entry.URL, 0); err != nil {
+				logger.Error("Error adding feed entry to queue", err)
+			}
+		}
+	}
+}
+
+// feedSources returns the sitemaps and feeds of the crawl: its Feeds and,
+// with DiscoverFeeds, the sitemaps the robots.txt of each seed's site lists,
+// or its /sitemap.xml if it lists none, and the feeds the seeds link to.
+func (s *crawlSession) feedSources(seeds []string) []string {
+	sources := append([]string{}, s.options.Feeds...)
+	if !s.options.DiscoverFeeds {
+		return sources
+	}
+
+	roots := make(map[string]bool)
+	for _, seed := range seeds {
+		start, err := url.Parse(seed)
+		if err != nil {
+			continue
+		}
+
+		root := &url.URL{Scheme: start.Scheme, Host: start.Host}
+		if !roots[root.String()] {
+			roots[root.String()] = true
+
+			var sitemaps []string
+			if robots, err := s.fetch(root.JoinPath("robots.txt").String()); err == nil {
+				sitemaps = feed.RobotsSitemaps(robots)
+			}
+			if len(sitemaps) == 0 {
+				sitemaps = []string{root.JoinPath("sitemap.xml").String()}
+			}
+			sources = append(sources, sitemaps...)
+		}
+
+		sources = append(sources, s.linkedFeeds(start)...)
+	}
+
+	return sources
+}
+
+// linkedFeeds returns the RSS and Atom feeds the page at start links to.
+func (s *crawlSession) linkedFeeds(start *url.URL) []string {
+	page, err := s.fetch(start.String())
+	if err != nil {
+		return nil
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
+	if err != nil {
+		return nil
+	}
+
+	var feeds []string
+	doc.Find(feedLinkSelector).Each(func(_ int, link *goquery.Selection) {
+		if href, err := start.Parse(link.AttrOr("href", "")); err == nil && href.String() != start.String() {
+			feeds = append(feeds, href.String())
+		}
+	})
+	return feeds
+}
+
+// handleFeedEntry matches a page listed by the sitemap or feed at source as
+// a link, with its title as anchor text, and records its title and dates if
+// it matches. It reports whether the entry matched.
+func (s *crawlSession) handleFeedEntry(entry feed.Entry, source string) bool {
+	s.stats.LinkStats.IncrementTotalLinks()
+
+	// Like pages, entries are in the language of the crawl, or of the feed or
+	// their title
+	links := s.LinkMatcher
+	if s.language == "" {
+		lang := language.Normalize(entry.Language)
+		if lang == "" {
+			lang = language.Detect(entry.Title)
+		}
+		links = s.inLanguage(lang)
+	}
+
+	matchingTerms := links.linkMatchingTerms(entry.URL, entry.Title)
+	if len(matchingTerms) == 0 {
+		s.updateStats(matchingTerms)
+		return false
+	}
+
+	pageData := createPageData(entry.URL)
+	pageData.Title = entry.Title
+	pageData.PublishedTime = entry.Published
+	pageData.ModifiedTime = entry.Modified
+	pageData.MatchLocations = links.linkMatchLocations(entry.URL, entry.Title)
+	pageData.Relevance = links.linkRelevance(entry.URL, entry.Title, matchingTerms)
+	_ = s.handleMatchingTerms(source, pageData, matchingTerms)
+	return true
+}
+
+// fetch gets a sitemap, feed or page with a clone of the collector, which
+// shares its limits, transport and robots.txt rules but not its callbacks,
+// and does not mark the URL visited. The request counts against the budget
+// of the crawl like any other.
+func (s *crawlSession) fetch(rawURL string) ([]byte, error) {
+	if s.ctx.Err() != nil || !s.reservePage() {
+		return nil, errOutOfBudget
+	}
+
+	c := s.collector.GetCollector().Clone()
+	c.AllowURLRevisit = true
+	c.Async = false
+	c.MaxDepth = 0
+	c.MaxBodySize = feed.MaxSize
+
+	var body []byte
+	c.OnResponse(func(r *colly.Response) {
+		body = r.Body
+	})
+	if err := c.Visit(rawURL); err != nil {
+		return nil, fmt.Errorf("%s: %v", rawURL, err)
+	}
+	return body, nil
+}
@@ -0,0 +1,60 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jonesrussell/page-prowler/models"
+)
+
+// loadHistory loads the URLs the earlier crawls of the site saw, which an
+// incremental crawl follows but does not report again.
+func (s *crawlSession) loadHistory() error {
+	if !s.options.Incremental {
+		return nil
+	}
+
+	history, err := s.manager.DBManager.GetSeenURLs(s.ctx, s.options.CrawlSiteID)
+	if err != nil {
+		return fmt.Errorf("failed to get seen URLs: %v", err)
+	}
+	s.history = history
+	s.manager.Logger.Debug("[loadHistory]", "urls", len(history))
+
+	return nil
+}
+
+// known reports whether an earlier crawl of the site saw rawURL.
+func (s *crawlSession) known(rawURL string) bool {
+	_, ok := s.history[rawURL]
+	return ok
+}
+
+// stamp records that the crawl found the result, for the results to tell
+// the links found by the latest crawl from those found before.
+func (s *crawlSession) stamp(pageData *models.PageData) {
+	started := s.run.StartedAt
+	pageData.FirstSeen = &started
+	pageData.RunID = s.run.ID
+}
+
+// saveHistory adds the pages the crawl fetched and the links it matched to
+// the URLs seen by the crawls of the site, with the pages crawled before the
+// crawl was resumed.
+func (s *crawlSession) saveHistory() error {
+	s.mu.Lock()
+	urls := make([]string, 0, len(s.metadata)+len(s.results.Pages))
+	for pageURL := range s.metadata {
+		urls = append(urls, pageURL)
+	}
+	for _, page := range s.results.Pages {
+		urls = append(urls, page.URL)
+	}
+	if s.resumed != nil {
+		urls = append(urls, s.resumed.Visited...)
+	}
+	s.mu.Unlock()
+
+	return s.manager.DBManager.SaveSeenURLs(context.Background(), s.options.CrawlSiteID, urls, time.Now().UTC())
+}
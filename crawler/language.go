@@ -0,0 +1,42 @@
+package crawler
+
+import (
+	"github.com/gocolly/colly"
+	"github.com/jonesrussell/page-prowler/internal/language"
+)
+
+// pageLinkMatcher returns the LinkMatcher for the links and content of the
+// page of e, which processes text in the language of the page unless the
+// crawl has a language.
+func (s *crawlSession) pageLinkMatcher(e *colly.HTMLElement) *LinkMatcher {
+	if s.language != "" {
+		return s.LinkMatcher
+	}
+	return s.inLanguage(s.pageLanguage(e))
+}
+
+// pageLanguage returns the language of e: the lang attribute of e or its
+// closest ancestor with one or, if that is missing or not supported, the
+// language detected in the content of its page.
+func (s *crawlSession) pageLanguage(e *colly.HTMLElement) string {
+	if lang := language.Normalize(e.DOM.Closest("[lang]").AttrOr("lang", "")); lang != "" {
+		return lang
+	}
+
+	pageURL := e.Request.URL.String()
+
+	s.mu.Lock()
+	lang, ok := s.languages[pageURL]
+	s.mu.Unlock()
+	if ok {
+		return lang
+	}
+
+	lang = language.Detect(extractContent(e.DOM.Closest("html")))
+
+	s.mu.Lock()
+	s.languages[pageURL] = lang
+	s.mu.Unlock()
+
+	return lang
+}
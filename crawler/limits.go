@@ -0,0 +1,124 @@
+package crawler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+// DomainLimit overrides the request limits for the domains matching DomainGlob.
+// Zero values fall back to the limits of the crawl.
+type DomainLimit struct {
+	DomainGlob  string        `json:"domain_glob"`
+	Parallelism int           `json:"parallelism"`
+	Delay       time.Duration `json:"delay"`
+	RandomDelay time.Duration `json:"random_delay"`
+}
+
+// ParseDomainLimit parses a domain limit in the form
+// "glob=parallelism[/delay[/randomdelay]]", e.g. "*cp24.com=1/5s/2s".
+func ParseDomainLimit(s string) (DomainLimit, error) {
+	glob, limits, found := strings.Cut(s, "=")
+	if !found || glob == "" || limits == "" {
+		return DomainLimit{}, fmt.Errorf("invalid domain limit %q: expected glob=parallelism[/delay[/randomdelay]]", s)
+	}
+
+	parts := strings.Split(limits, "/")
+	if len(parts) > 3 {
+		return DomainLimit{}, fmt.Errorf("invalid domain limit %q: too many values", s)
+	}
+
+	limit := DomainLimit{DomainGlob: glob}
+
+	parallelism, err := strconv.Atoi(parts[0])
+	if err != nil || parallelism < 0 {
+		return DomainLimit{}, fmt.Errorf("invalid parallelism in domain limit %q", s)
+	}
+	limit.Parallelism = parallelism
+
+	if len(parts) > 1 {
+		limit.Delay, err = time.ParseDuration(parts[1])
+		if err != nil {
+			return DomainLimit{}, fmt.Errorf("invalid delay in domain limit %q: %v", s, err)
+		}
+	}
+
+	if len(parts) > 2 {
+		limit.RandomDelay, err = time.ParseDuration(parts[2])
+		if err != nil {
+			return DomainLimit{}, fmt.Errorf("invalid random delay in domain limit %q: %v", s, err)
+		}
+	}
+
+	return limit, nil
+}
+
+// ParseDomainLimits parses each of the given domain limits.
+func ParseDomainLimits(values []string) ([]DomainLimit, error) {
+	limits := make([]DomainLimit, 0, len(values))
+	for _, value := range values {
+		limit, err := ParseDomainLimit(value)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, limit)
+	}
+	return limits, nil
+}
+
+// parallelism returns the number of concurrent requests allowed per domain.
+// Validate rejects a MaxConcurrentRequests of 0, which options built in code
+// are left with when they do not set it.
+func (o *CrawlOptions) parallelism() int {
+	if o.MaxConcurrentRequests > 0 {
+		return o.MaxConcurrentRequests
+	}
+	return DefaultParallelism
+}
+
+// queueThreads returns the number of queue consumers needed to reach the
+// highest parallelism allowed for any domain.
+func (o *CrawlOptions) queueThreads() int {
+	threads := o.parallelism()
+	for _, limit := range o.DomainLimits {
+		if limit.Parallelism > threads {
+			threads = limit.Parallelism
+		}
+	}
+	return threads
+}
+
+// limitRules returns the colly limit rules for the crawl. Domain overrides come
+// first since colly applies the first rule that matches a domain.
+func (o *CrawlOptions) limitRules() []*colly.LimitRule {
+	rules := make([]*colly.LimitRule, 0, len(o.DomainLimits)+1)
+
+	for _, limit := range o.DomainLimits {
+		rule := &colly.LimitRule{
+			DomainGlob:  limit.DomainGlob,
+			Parallelism: o.parallelism(),
+			Delay:       o.DelayBetweenRequests,
+			RandomDelay: o.RandomDelay,
+		}
+		if limit.Parallelism > 0 {
+			rule.Parallelism = limit.Parallelism
+		}
+		if limit.Delay > 0 {
+			rule.Delay = limit.Delay
+		}
+		if limit.RandomDelay > 0 {
+			rule.RandomDelay = limit.RandomDelay
+		}
+		rules = append(rules, rule)
+	}
+
+	return append(rules, &colly.LimitRule{
+		DomainGlob:  "*",
+		Parallelism: o.parallelism(),
+		Delay:       o.DelayBetweenRequests,
+		RandomDelay: o.RandomDelay,
+	})
+}
@@ -0,0 +1,187 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/queue"
+	"github.com/golang/mock/gomock"
+	"github.com/jonesrussell/loggo"
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDomainLimit(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    DomainLimit
+		wantErr bool
+	}{
+		{input: "*cp24.com=1", want: DomainLimit{DomainGlob: "*cp24.com", Parallelism: 1}},
+		{input: "*cp24.com=1/5s", want: DomainLimit{DomainGlob: "*cp24.com", Parallelism: 1, Delay: 5 * time.Second}},
+		{input: "*=4/1s/500ms", want: DomainLimit{DomainGlob: "*", Parallelism: 4, Delay: time.Second, RandomDelay: 500 * time.Millisecond}},
+		{input: "*cp24.com", wantErr: true},
+		{input: "=1", wantErr: true},
+		{input: "*cp24.com=two", wantErr: true},
+		{input: "*cp24.com=1/soon", wantErr: true},
+		{input: "*cp24.com=1/1s/1s/1s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDomainLimit(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLimitRules(t *testing.T) {
+	options := &CrawlOptions{
+		MaxConcurrentRequests: 4,
+		DelayBetweenRequests:  time.Second,
+		RandomDelay:           200 * time.Millisecond,
+		DomainLimits: []DomainLimit{
+			{DomainGlob: "*cp24.com", Parallelism: 1, Delay: 5 * time.Second},
+			{DomainGlob: "*ctvnews.ca", Parallelism: 8},
+		},
+	}
+
+	rules := options.limitRules()
+	require.Len(t, rules, 3)
+
+	assert.Equal(t, "*cp24.com", rules[0].DomainGlob)
+	assert.Equal(t, 1, rules[0].Parallelism)
+	assert.Equal(t, 5*time.Second, rules[0].Delay)
+	assert.Equal(t, 200*time.Millisecond, rules[0].RandomDelay)
+
+	assert.Equal(t, "*ctvnews.ca", rules[1].DomainGlob)
+	assert.Equal(t, 8, rules[1].Parallelism)
+	assert.Equal(t, time.Second, rules[1].Delay)
+
+	assert.Equal(t, "*", rules[2].DomainGlob)
+	assert.Equal(t, 4, rules[2].Parallelism)
+	assert.Equal(t, time.Second, rules[2].Delay)
+
+	assert.Equal(t, 8, options.queueThreads())
+
+	defaults := (&CrawlOptions{}).limitRules()
+	require.Len(t, defaults, 1)
+	assert.Equal(t, DefaultParallelism, defaults[0].Parallelism)
+	assert.Zero(t, defaults[0].Delay)
+}
+
+// concurrencyServer records the highest number of requests it served at once.
+type concurrencyServer struct {
+	mu       sync.Mutex
+	inFlight int
+	max      int
+	starts   []time.Time
+}
+
+func (s *concurrencyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/robots.txt" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	s.inFlight++
+	if s.inFlight > s.max {
+		s.max = s.inFlight
+	}
+	s.starts = append(s.starts, time.Now())
+	s.mu.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/html")
+	fmt.Fprint(w, "<html><body></body></html>")
+}
+
+func runLimitedCrawl(t *testing.T, options *CrawlOptions, pages int) *concurrencyServer {
+	t.Helper()
+
+	handler := &concurrencyServer{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	logger := loggo.NewMockLoggerInterface(ctrl)
+	logger.EXPECT().Debug(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+
+	cm := NewCrawlManager(logger, dbmanager.NewMockDBManager(), nil, options)
+	session, err := cm.newCrawlSession(context.Background(), newCrawlRun(options), options)
+	require.NoError(t, err)
+	defer session.close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, session.configureCollector([]string{serverURL.Host}))
+
+	q, err := queue.New(options.queueThreads(), &queue.InMemoryQueueStorage{MaxSize: 100})
+	require.NoError(t, err)
+	for i := 0; i < pages; i++ {
+		require.NoError(t, q.AddURL(fmt.Sprintf("%s/page-%d", server.URL, i)))
+	}
+	require.NoError(t, q.Run(session.collector.GetCollector()))
+
+	return handler
+}
+
+func TestConfigureCollectorHonorsMaxConcurrentRequests(t *testing.T) {
+	serial := runLimitedCrawl(t, &CrawlOptions{MaxConcurrentRequests: 1, DelayBetweenRequests: time.Millisecond}, 4)
+	assert.Equal(t, 1, serial.max)
+	assert.Len(t, serial.starts, 4)
+
+	parallel := runLimitedCrawl(t, &CrawlOptions{MaxConcurrentRequests: 3, DelayBetweenRequests: time.Millisecond}, 6)
+	assert.Greater(t, parallel.max, 1)
+	assert.LessOrEqual(t, parallel.max, 3)
+	assert.Len(t, parallel.starts, 6)
+}
+
+func TestConfigureCollectorHonorsDelayBetweenRequests(t *testing.T) {
+	delay := 150 * time.Millisecond
+	handler := runLimitedCrawl(t, &CrawlOptions{MaxConcurrentRequests: 1, DelayBetweenRequests: delay}, 3)
+
+	require.Len(t, handler.starts, 3)
+	for i := 1; i < len(handler.starts); i++ {
+		assert.GreaterOrEqual(t, handler.starts[i].Sub(handler.starts[i-1]), delay)
+	}
+}
+
+func TestConfigureCollectorWithoutDelay(t *testing.T) {
+	start := time.Now()
+	handler := runLimitedCrawl(t, &CrawlOptions{MaxConcurrentRequests: 1}, 4)
+
+	// Each page takes 50ms to serve
+	assert.Len(t, handler.starts, 4)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestConfigureCollectorHonorsDomainLimits(t *testing.T) {
+	options := &CrawlOptions{
+		MaxConcurrentRequests: 4,
+		DelayBetweenRequests:  time.Millisecond,
+		DomainLimits:          []DomainLimit{{DomainGlob: "127.0.0.1*", Parallelism: 1}},
+	}
+	handler := runLimitedCrawl(t, options, 4)
+
+	assert.Equal(t, 1, handler.max)
+}
@@ -0,0 +1,135 @@
+package crawler
+
+import (
+	"context"
+
+	"github.com/jonesrussell/page-prowler/internal/termmatcher"
+	"github.com/jonesrussell/page-prowler/models"
+	"github.com/jonesrussell/page-prowler/utils"
+)
+
+// LinkMatcher matches links, by their URL and anchor text, and page content
+// as a crawl with the same options does.
+type LinkMatcher struct {
+	query       *termmatcher.Query // nil when the crawl matches searchTerms
+	searchTerms []string
+	language    string // the language of the crawl, "" to detect it per page
+
+	// termMatcher compares terms with the similarity metric of the crawl
+	termMatcher *termmatcher.TermMatcher
+}
+
+// NewLinkMatcher returns a LinkMatcher for the search terms or query, the
+// similarity and the matchers of options. Without search terms or a query,
+// the query saved for options.CrawlSiteID is used.
+func (cm *CrawlManager) NewLinkMatcher(ctx context.Context, options *CrawlOptions) (*LinkMatcher, error) {
+	query, err := cm.searchQuery(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return cm.newLinkMatcher(query, options)
+}
+
+// newLinkMatcher returns a LinkMatcher for query, or the search terms of
+// options if it is nil.
+func (cm *CrawlManager) newLinkMatcher(query *termmatcher.Query, options *CrawlOptions) (*LinkMatcher, error) {
+	termMatcher, err := cm.termMatcher(options)
+	if err != nil {
+		return nil, err
+	}
+
+	links := &LinkMatcher{
+		query:       query,
+		searchTerms: options.SearchTerms,
+		termMatcher: termMatcher,
+	}
+	if options.Language != "" {
+		links.language = termMatcher.Language()
+	}
+	return links, nil
+}
+
+// inLanguage returns m, or a copy of m that processes text in lang if the
+// crawl has no language of its own.
+func (m *LinkMatcher) inLanguage(lang string) *LinkMatcher {
+	if m.language != "" || lang == m.termMatcher.Language() {
+		return m
+	}
+	copied := *m
+	copied.termMatcher = m.termMatcher.WithLanguage(lang)
+	return &copied
+}
+
+// Match returns the result for a link, without the metadata of the page it
+// links to, and whether the link matches.
+func (m *LinkMatcher) Match(href string, anchorText string) (models.PageData, bool) {
+	matchingTerms := m.linkMatchingTerms(href, anchorText)
+	if len(matchingTerms) == 0 {
+		return models.PageData{}, false
+	}
+
+	pageData := createPageData(href)
+	pageData.MatchLocations = m.linkMatchLocations(href, anchorText)
+	pageData.Relevance = m.linkRelevance(href, anchorText, matchingTerms)
+	pageData.MatchingTerms = matchingTerms
+	return pageData, true
+}
+
+// Explain explains step by step why a link does or does not match.
+func (m *LinkMatcher) Explain(href string, anchorText string) *termmatcher.Explanation {
+	if m.query != nil {
+		return m.termMatcher.ExplainQuery(href, anchorText, m.query)
+	}
+	return m.termMatcher.Explain(href, anchorText, m.searchTerms)
+}
+
+// linkMatchingTerms returns the terms that match the URL and anchor text of a
+// link, using the query of the crawl if it has one.
+func (m *LinkMatcher) linkMatchingTerms(href string, anchorText string) []string {
+	if m.query != nil {
+		return m.termMatcher.GetQueryMatchingTerms(href, anchorText, m.query)
+	}
+	return m.termMatcher.GetMatchingTerms(href, anchorText, m.searchTerms)
+}
+
+// textMatchingTerms returns the terms that match text, using the query of the
+// crawl if it has one.
+func (m *LinkMatcher) textMatchingTerms(text string) []string {
+	if m.query != nil {
+		return m.termMatcher.GetQueryMatchingTermsInText(text, m.query)
+	}
+	return m.termMatcher.GetMatchingTermsInText(text, m.searchTerms)
+}
+
+// matchLocations returns the locations whose text matches on its own or, if
+// the texts only match together, all of them.
+func (m *LinkMatcher) matchLocations(locations []string, texts []string) []string {
+	var result []string
+	for i, text := range texts {
+		if len(m.textMatchingTerms(text)) > 0 {
+			result = append(result, locations[i])
+		}
+	}
+	if len(result) == 0 {
+		return locations
+	}
+	return result
+}
+
+// linkMatchLocations returns where in a matched link the search terms are,
+// its URL, its anchor text or both.
+func (m *LinkMatcher) linkMatchLocations(href string, anchorText string) []string {
+	return m.matchLocations(
+		[]string{models.MatchLocationURL, models.MatchLocationAnchor},
+		[]string{utils.ExtractLastSegmentFromURL(href), anchorText},
+	)
+}
+
+// linkRelevance scores how relevant a link is for the terms it matched.
+func (m *LinkMatcher) linkRelevance(href string, anchorText string, matchingTerms []string) float64 {
+	return m.termMatcher.Relevance(map[string]string{
+		models.MatchLocationURL:    utils.ExtractLastSegmentFromURL(href),
+		models.MatchLocationAnchor: anchorText,
+	}, matchingTerms)
+}
@@ -1,165 +1,185 @@
 package crawler
 
 import (
+	"context"
 	"fmt"
-	"sync"
+	"net/url"
 
-	"github.com/go-redis/redis"
-
-	"github.com/gocolly/colly"
 	"github.com/gocolly/colly/queue"
-	"github.com/gocolly/redisstorage"
 	"github.com/jonesrussell/loggo"
 	"github.com/jonesrussell/page-prowler/dbmanager"
+	_ "github.com/jonesrussell/page-prowler/internal/drug" // Registers the drug matcher
 	"github.com/jonesrussell/page-prowler/internal/matcher"
+	_ "github.com/jonesrussell/page-prowler/internal/mining" // Registers the mining matcher
 	"github.com/jonesrussell/page-prowler/internal/termmatcher"
+	"github.com/jonesrussell/page-prowler/models"
 	"github.com/jonesrussell/page-prowler/utils"
 )
 
 type CrawlManagerInterface interface {
-	Crawl() error
+	Crawl(ctx context.Context) error
+	CrawlWithOptions(ctx context.Context, options *CrawlOptions) error
 	GetDBManager() dbmanager.DatabaseManagerInterface
 	GetLogger() loggo.LoggerInterface
+	NewLinkMatcher(ctx context.Context, options *CrawlOptions) (*LinkMatcher, error)
+	ResumeCrawl(ctx context.Context, runID string) error
 	SetOptions(options *CrawlOptions) error
-	UpdateStats(options *CrawlOptions, matchingTerms []string)
 }
 
+// CrawlManager holds the dependencies shared by all crawls. The state of each
+// crawl lives in its own crawlSession, so a CrawlManager can run any number of
+// crawls, one after another or at the same time.
 type CrawlManager struct {
-	CollectorInstance *CollectorWrapper
-	CrawlingMu        *sync.Mutex
-	DBManager         dbmanager.DatabaseManagerInterface
-	Logger            loggo.LoggerInterface
-	Options           *CrawlOptions
-	Results           *Results
-	StatsManager      *StatsManager
-	Storage           *redisstorage.Storage
-	TermMatcher       *termmatcher.TermMatcher // Ensure TermMatcher is included
+	CollectorFactory CollectorFactory
+	DBManager        dbmanager.DatabaseManagerInterface
+	Logger           loggo.LoggerInterface
+	Matchers         *matcher.Registry // the matchers crawls can enable by name
+	Options          *CrawlOptions
+	TermMatcher      *termmatcher.TermMatcher // Ensure TermMatcher is included
 }
 
 var _ CrawlManagerInterface = &CrawlManager{}
 
+// NewCrawlManager creates a new CrawlManager. If collectorFactory is nil,
+// crawls use DefaultCollectorFactory.
 func NewCrawlManager(
 	logger loggo.LoggerInterface,
 	dbManager dbmanager.DatabaseManagerInterface,
-	collectorInstance *CollectorWrapper,
+	collectorFactory CollectorFactory,
 	options *CrawlOptions,
-	storage *redisstorage.Storage,
 ) *CrawlManager {
+	if collectorFactory == nil {
+		collectorFactory = DefaultCollectorFactory(logger)
+	}
+
 	return &CrawlManager{
-		Logger:            logger,
-		DBManager:         dbManager,
-		CollectorInstance: collectorInstance,
-		CrawlingMu:        &sync.Mutex{},
-		Options:           options,
-		Results:           NewResults(),
-		Storage:           storage,
-		TermMatcher:       termmatcher.NewTermMatcher(logger, []matcher.Matcher{}), // Initialize TermMatcher with empty matcher slice
-		StatsManager:      NewStatsManager(),
+		CollectorFactory: collectorFactory,
+		Logger:           logger,
+		DBManager:        dbManager,
+		Matchers:         matcher.DefaultRegistry,
+		Options:          options,
+		TermMatcher:      termmatcher.NewTermMatcher(logger, []matcher.Matcher{}), // Initialize TermMatcher with empty matcher slice
 	}
 }
 
-func (cm *CrawlManager) Crawl() error {
+// Crawl crawls using the options set with SetOptions.
+func (cm *CrawlManager) Crawl(ctx context.Context) error {
+	return cm.CrawlWithOptions(ctx, cm.GetOptions())
+}
+
+// CrawlWithOptions crawls using the given options. It is safe to call
+// concurrently.
+//
+// The crawl stops early when ctx is done, returning ctx.Err(), or when it
+// runs out of its MaxDuration or MaxPages budget, returning nil. Either way
+// the requests still queued are dropped and the statistics gathered so far
+// are saved. A crawl that does not complete can be resumed with ResumeCrawl.
+func (cm *CrawlManager) CrawlWithOptions(ctx context.Context, options *CrawlOptions) error {
 	cm.Logger.Info("[Crawl] Starting Crawl function")
 
-	options := cm.GetOptions()
+	return cm.crawl(ctx, newCrawlRun(options), options, nil)
+}
 
-	startURL := options.StartURL
+// crawl runs a crawl, from its start URLs or, when checkpoint is not nil,
+// from where the run was interrupted.
+func (cm *CrawlManager) crawl(ctx context.Context, run *models.CrawlRun, options *CrawlOptions, checkpoint *models.CrawlCheckpoint) (err error) {
+	cm.startCrawlRun(run)
 
-	cm.initializeStatsManager()
+	var session *crawlSession
+	defer func() {
+		cm.finishCrawlRun(run, session, err)
+	}()
 
-	host, err := utils.GetHostFromURL(startURL)
+	crawlCtx, cancel := options.crawlContext(ctx)
+	defer cancel()
+
+	query, err := cm.searchQuery(ctx, options)
 	if err != nil {
 		return err
 	}
 
-	cm.Logger.Debug("options", "MaxDepth", options.MaxDepth)
-	if err := cm.configureCollector([]string{host}, options.MaxDepth); err != nil {
+	session, err = cm.newCrawlSession(crawlCtx, run, options)
+	if err != nil {
 		return err
 	}
+	defer session.close()
+	session.query = query
 
-	// Create a new request queue with the Redis storage backend
-	q, err := queue.New(2, cm.Storage)
-	if err != nil {
-		return fmt.Errorf("failed to create queue: %v", err)
+	if err := session.loadHistory(); err != nil {
+		return err
 	}
 
-	// Add the start URL to the queue
-	err = q.AddURL(startURL)
+	domains, err := options.allowedDomains()
 	if err != nil {
-		return fmt.Errorf("failed to add URL to queue: %v", err)
+		return err
 	}
 
-	// Consume requests
-	err = q.Run(cm.CollectorInstance.GetCollector())
+	cm.Logger.Debug("options", "MaxDepth", options.MaxDepth)
+	if err := session.configureCollector(domains); err != nil {
+		return err
+	}
+
+	// Create a new request queue with the crawl's storage backend
+	q, err := queue.New(options.queueThreads(), session.storage)
 	if err != nil {
-		return fmt.Errorf("failed to run queue: %v", err)
+		return fmt.Errorf("failed to create queue: %v", err)
 	}
 
-	// close redis client
-	defer func(Client *redis.Client) {
-		err := Client.Close()
-		if err != nil {
-			fmt.Printf("failed to close redis client: %v", err)
+	if checkpoint != nil {
+		// Carry on with the pages the interrupted crawl had queued
+		if err := session.restore(checkpoint); err != nil {
+			return err
+		}
+		for _, page := range checkpoint.Queue {
+			if err := session.enqueue(q, page.URL, page.Depth); err != nil {
+				return fmt.Errorf("failed to add URL to queue: %v", err)
+			}
+		}
+	} else {
+		// Add the start URLs to the queue
+		seeds := options.Seeds()
+		for _, seed := range seeds {
+			if err := session.enqueue(q, seed, 0); err != nil {
+				return fmt.Errorf("failed to add URL to queue: %v", err)
+			}
 		}
-	}(cm.Storage.Client)
-
-	cm.Logger.Info("[Crawl] Crawling completed.")
-
-	return nil
-}
 
-func (cm *CrawlManager) configureCollector(allowedDomains []string, maxDepth int) error {
-	cm.Logger.Debug("[configureCollector]", "maxDepth", maxDepth)
+		// Add the pages listed by the sitemaps and feeds of the sites
+		session.seedFromFeeds(q, seeds)
+	}
+	session.queued.Store(true)
 
-	// Get the underlying colly.Collector from the CollectorWrapper
-	collector := cm.CollectorInstance.GetCollector()
+	// Consume requests
+	err = q.Run(session.collector.GetCollector())
+	if err != nil {
+		return fmt.Errorf("failed to run queue: %v", err)
+	}
 
-	collector.AllowedDomains = allowedDomains
-	cm.Logger.Info("Allowed domains: ", "whitelist", allowedDomains)
+	// Record the metadata of the matched pages the crawl did not reach
+	session.fetchPendingMetadata()
 
-	collector.AllowURLRevisit = false
-	collector.Async = false
-	collector.IgnoreRobotsTxt = false
-	collector.MaxDepth = maxDepth
+	// Remember what the crawl saw, for incremental crawls and to tell new
+	// links from old ones
+	if err := session.saveHistory(); err != nil {
+		return fmt.Errorf("failed to save seen URLs: %v", err)
+	}
 
-	limitRule := &colly.LimitRule{
-		DomainGlob:  "*",
-		Parallelism: DefaultParallelism,
-		Delay:       DefaultDelay,
+	// Persist the statistics so they can be reported after the process exits,
+	// even if the crawl was cancelled
+	err = cm.DBManager.SaveStats(context.Background(), options.CrawlSiteID, session.stats.LinkStats)
+	if err != nil {
+		return fmt.Errorf("failed to save stats: %v", err)
 	}
 
-	if err := collector.Limit(limitRule); err != nil {
+	if err := ctx.Err(); err != nil {
+		cm.Logger.Info("[Crawl] Crawling cancelled.")
 		return err
 	}
+	if crawlCtx.Err() != nil || session.budgetExhausted() {
+		cm.Logger.Info("[Crawl] Crawl budget reached.", "maxDuration", options.MaxDuration.String(), "maxPages", options.MaxPages)
+	}
 
-	collector.OnHTML("a[href]", func(e *colly.HTMLElement) {
-		href, err := cm.getHref(e)
-		if err != nil {
-			return
-		}
-		if href == "" {
-			return
-		}
-
-		// Use TermMatcher to find matching terms in the URL and anchor text
-		matchingTerms := cm.TermMatcher.GetMatchingTerms(href, e.Text, cm.Options.SearchTerms)
-		if len(matchingTerms) > 0 {
-			pageData := cm.createPageData(href)
-			err := cm.handleMatchingTerms(cm.Options, e.Request.URL.String(), pageData, matchingTerms)
-			if err != nil {
-				return
-			}
-		}
-
-		err = e.Request.Visit(href)
-		if err != nil {
-			return
-		}
-	})
-
-	collector.OnError(func(r *colly.Response, err error) {
-		fmt.Println("Request URL:", r.Request.URL, "failed with response:", r, "\nError:", err)
-	})
+	cm.Logger.Info("[Crawl] Crawling completed.")
 
 	return nil
 }
@@ -167,3 +187,18 @@ func (cm *CrawlManager) configureCollector(allowedDomains []string, maxDepth int
 func (cm *CrawlManager) GetDBManager() dbmanager.DatabaseManagerInterface {
 	return cm.DBManager
 }
+
+// allowedHost returns the host of startURL as colly compares it against the
+// allowed domains, including any explicit port.
+func allowedHost(startURL string) (string, error) {
+	host, err := utils.GetHostFromURL(startURL)
+	if err != nil {
+		return "", err
+	}
+
+	if u, err := url.Parse(startURL); err == nil && u.Port() != "" {
+		host = u.Host
+	}
+
+	return host, nil
+}
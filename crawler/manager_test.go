@@ -0,0 +1,704 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonesrussell/loggo"
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/jonesrussell/page-prowler/internal/language"
+	"github.com/jonesrussell/page-prowler/internal/matcher"
+	"github.com/jonesrussell/page-prowler/internal/termmatcher"
+	"github.com/jonesrussell/page-prowler/internal/topic"
+	"github.com/jonesrussell/page-prowler/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSiteServer serves a home page linking to three articles, one of which
+// matches the term "flood".
+func newSiteServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", http.NotFound)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path != "/" {
+			fmt.Fprint(w, "<html><body><p>article</p></body></html>")
+			return
+		}
+		fmt.Fprint(w, `<html><body>
+			<a href="/news/flood-warning-downtown">Flood warning</a>
+			<a href="/news/weather">Weather</a>
+			<a href="/news/sports">Sports</a>
+		</body></html>`)
+	})
+	return httptest.NewServer(mux)
+}
+
+func newTestCrawlManager(t *testing.T) (*CrawlManager, *dbmanager.MockDBManager) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	logger := loggo.NewMockLoggerInterface(ctrl)
+	logger.EXPECT().Debug(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+
+	dbManager := dbmanager.NewMockDBManager()
+	return NewCrawlManager(logger, dbManager, nil, &CrawlOptions{}), dbManager
+}
+
+func assertRunStats(t *testing.T, dbManager *dbmanager.MockDBManager, siteID string) {
+	t.Helper()
+
+	runs, err := dbManager.ListCrawlRuns(context.Background(), siteID)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+
+	run := runs[0]
+	assert.Equal(t, models.CrawlRunStatusCompleted, run.Status)
+	assert.Equal(t, 4, run.TotalPages)
+	assert.Equal(t, 3, run.TotalLinks)
+	assert.Equal(t, 1, run.MatchedLinks)
+	assert.Equal(t, 2, run.NotMatchedLinks)
+}
+
+func TestCrawlManagerReusedSequentially(t *testing.T) {
+	server := newSiteServer()
+	defer server.Close()
+
+	cm, dbManager := newTestCrawlManager(t)
+
+	for i := 0; i < 2; i++ {
+		siteID := fmt.Sprintf("site-%d", i)
+		require.NoError(t, cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+			CrawlSiteID:          siteID,
+			StartURL:             server.URL,
+			SearchTerms:          []string{"flood"},
+			MaxDepth:             2,
+			DelayBetweenRequests: time.Millisecond,
+		}))
+
+		// Callbacks from the previous crawl must not double count
+		assertRunStats(t, dbManager, siteID)
+	}
+}
+
+func TestCrawlManagerConcurrentCrawls(t *testing.T) {
+	server := newSiteServer()
+	defer server.Close()
+
+	cm, dbManager := newTestCrawlManager(t)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+				CrawlSiteID:          fmt.Sprintf("site-%d", i),
+				StartURL:             server.URL,
+				SearchTerms:          []string{"flood"},
+				MaxDepth:             2,
+				DelayBetweenRequests: time.Millisecond,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		require.NoError(t, err)
+		assertRunStats(t, dbManager, fmt.Sprintf("site-%d", i))
+	}
+}
+
+// newLinkFarmServer serves a home page linking to n pages that each take
+// delay to respond.
+func newLinkFarmServer(n int, delay time.Duration) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", http.NotFound)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path != "/" {
+			time.Sleep(delay)
+			fmt.Fprint(w, "<html><body><p>page</p></body></html>")
+			return
+		}
+		fmt.Fprint(w, "<html><body>")
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(w, `<a href="/page-%d">Page %d</a>`, i, i)
+		}
+		fmt.Fprint(w, "</body></html>")
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestCrawlWithOptionsBudgets(t *testing.T) {
+	server := newLinkFarmServer(20, 20*time.Millisecond)
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		options  CrawlOptions
+		maxPages int
+	}{
+		{name: "max pages", options: CrawlOptions{MaxPages: 3}, maxPages: 3},
+		{name: "max duration", options: CrawlOptions{MaxDuration: 150 * time.Millisecond}, maxPages: 19},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm, dbManager := newTestCrawlManager(t)
+
+			options := tt.options
+			options.CrawlSiteID = "budget"
+			options.StartURL = server.URL
+			options.SearchTerms = []string{"flood"}
+			options.MaxDepth = 2
+			options.MaxConcurrentRequests = 1
+			options.DelayBetweenRequests = time.Millisecond
+
+			require.NoError(t, cm.CrawlWithOptions(context.Background(), &options))
+
+			runs, err := dbManager.ListCrawlRuns(context.Background(), "budget")
+			require.NoError(t, err)
+			require.Len(t, runs, 1)
+			assert.Equal(t, models.CrawlRunStatusCompleted, runs[0].Status)
+			assert.Greater(t, runs[0].TotalPages, 0)
+			assert.LessOrEqual(t, runs[0].TotalPages, tt.maxPages)
+		})
+	}
+}
+
+func TestCrawlWithOptionsCancelled(t *testing.T) {
+	server := newLinkFarmServer(20, 100*time.Millisecond)
+	defer server.Close()
+
+	cm, dbManager := newTestCrawlManager(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(250*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := cm.CrawlWithOptions(ctx, &CrawlOptions{
+		CrawlSiteID:           "cancelled",
+		StartURL:              server.URL,
+		SearchTerms:           []string{"flood"},
+		MaxDepth:              2,
+		MaxConcurrentRequests: 1,
+		DelayBetweenRequests:  time.Millisecond,
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+
+	runs, err := dbManager.ListCrawlRuns(context.Background(), "cancelled")
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, models.CrawlRunStatusCancelled, runs[0].Status)
+	assert.Less(t, runs[0].TotalPages, 21)
+
+	// The statistics gathered before cancelling are kept
+	assert.NotNil(t, dbManager.SavedStats)
+}
+
+func TestConfigureCollectorKeepsTransport(t *testing.T) {
+	cm, _ := newTestCrawlManager(t)
+	options := &CrawlOptions{StartURL: "https://example.com", MaxDepth: 1}
+	session, err := cm.newCrawlSession(context.Background(), newCrawlRun(options), options)
+	require.NoError(t, err)
+	base := session.collector.Transport()
+
+	require.NoError(t, session.configureCollector([]string{"example.com"}))
+
+	// Cancelling is added to the transport with the dial timeout of the
+	// collector, not to the default transport
+	transport, ok := session.collector.Transport().(*contextTransport)
+	require.True(t, ok)
+	assert.Same(t, base, transport.base)
+	assert.NotSame(t, http.DefaultTransport, transport.base)
+}
+
+func TestCrawlWithOptionsQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		savedQuery string
+		wantURLs   []string
+		wantErr    error
+	}{
+		{
+			name:     "query",
+			query:    "(flood OR weather) -downtown",
+			wantURLs: []string{"/news/weather"},
+		},
+		{
+			name:       "saved query",
+			savedQuery: "sports OR flood",
+			wantURLs:   []string{"/news/flood-warning-downtown", "/news/sports"},
+		},
+		{
+			name:    "no search terms",
+			wantErr: ErrNoSearchTerms,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSiteServer()
+			defer server.Close()
+
+			cm, dbManager := newTestCrawlManager(t)
+			require.NoError(t, dbManager.SaveQuery(context.Background(), "site", tt.savedQuery))
+
+			err := cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+				CrawlSiteID:          "site",
+				StartURL:             server.URL,
+				Query:                tt.query,
+				MaxDepth:             1,
+				DelayBetweenRequests: time.Millisecond,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var urls []string
+			for _, result := range dbManager.SavedResults {
+				urls = append(urls, strings.TrimPrefix(result.URL, server.URL))
+			}
+			assert.ElementsMatch(t, tt.wantURLs, urls)
+		})
+	}
+}
+
+func TestCrawlWithOptionsMatchers(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", http.NotFound)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path != "/" {
+			fmt.Fprint(w, "<html><body><p>article</p></body></html>")
+			return
+		}
+		fmt.Fprint(w, `<html><body>
+			<a href="/news/police-seize-drug-shipment">Police seize shipment</a>
+			<a href="/news/weather">Weather</a>
+			<a href="/news/council-meeting">Council meeting</a>
+		</body></html>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		searchTerms []string
+		matchers    []string
+		want        map[string][]string
+		wantErr     error
+	}{
+		{
+			name:     "matchers only",
+			matchers: []string{"drug"},
+			want:     map[string][]string{"/news/police-seize-drug-shipment": {"drug"}},
+		},
+		{
+			name:        "matchers and search terms",
+			searchTerms: []string{"weather"},
+			matchers:    []string{"drug"},
+			want: map[string][]string{
+				"/news/police-seize-drug-shipment": {"drug"},
+				"/news/weather":                    {"weather"},
+			},
+		},
+		{
+			name:     "unknown matcher",
+			matchers: []string{"sports"},
+			wantErr:  matcher.ErrUnknownMatcher,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm, dbManager := newTestCrawlManager(t)
+
+			err := cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+				CrawlSiteID:          "site",
+				StartURL:             server.URL,
+				SearchTerms:          tt.searchTerms,
+				Matchers:             tt.matchers,
+				MaxDepth:             1,
+				DelayBetweenRequests: time.Millisecond,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			got := make(map[string][]string)
+			for _, result := range dbManager.SavedResults {
+				got[strings.TrimPrefix(result.URL, server.URL)] = result.MatchingTerms
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConfigureTermMatcherTopicThreshold(t *testing.T) {
+	registry := matcher.NewRegistry()
+	topic.Register(registry, topic.MustParse([]byte(`{"name": "drugs", "threshold": 0.8, "include": ["fentanyl"]}`), ".json"))
+
+	ctrl := gomock.NewController(t)
+	logger := loggo.NewMockLoggerInterface(ctrl)
+	logger.EXPECT().Debug(gomock.Any(), gomock.Any()).AnyTimes()
+	base := termmatcher.NewTermMatcher(logger, nil)
+
+	const href = "https://example.com/news/fentanil-seized-at-border"
+
+	// The metric is always set by the CLI, the threshold of the dictionary
+	// applies unless one is given too
+	tm, err := ConfigureTermMatcher(base, registry, &CrawlOptions{
+		SimilarityMetric: matcher.MetricSmithWatermanGotoh,
+		Matchers:         []string{"drugs"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"drugs"}, tm.GetMatchingTerms(href, "", nil))
+
+	tm, err = ConfigureTermMatcher(base, registry, &CrawlOptions{
+		SimilarityMetric:    matcher.MetricSmithWatermanGotoh,
+		SimilarityThreshold: 0.9,
+		Matchers:            []string{"drugs"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, tm.GetMatchingTerms(href, "", nil))
+}
+
+func TestCrawlWithOptionsLanguage(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", http.NotFound)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html lang="fr-CA"><body>
+				<a href="/actualites/les-hopitaux-debordes">Urgences</a>
+				<a href="/noticias">Noticias</a>
+			</body></html>`)
+		case "/noticias":
+			fmt.Fprint(w, `<html><body>
+				<article><p>La policía de la ciudad y los bomberos trabajan en la zona con los vecinos de la frontera.</p></article>
+				<a href="/sociedad/detenidos-dos-policias-por-trafico">Detenidos</a>
+			</body></html>`)
+		default:
+			fmt.Fprint(w, "<html><body><p>article</p></body></html>")
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		language string
+		want     map[string][]string
+	}{
+		{
+			name: "detected per page",
+			want: map[string][]string{
+				"/actualites/les-hopitaux-debordes":            {"hôpital"},
+				"/sociedad/detenidos-dos-policias-por-trafico": {"policía"},
+			},
+		},
+		{
+			name:     "language of the site",
+			language: "es",
+			want: map[string][]string{
+				"/sociedad/detenidos-dos-policias-por-trafico": {"policía"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm, dbManager := newTestCrawlManager(t)
+
+			err := cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+				CrawlSiteID:          "site",
+				StartURL:             server.URL,
+				SearchTerms:          []string{"hôpital", "policía"},
+				Language:             tt.language,
+				MaxDepth:             2,
+				DelayBetweenRequests: time.Millisecond,
+			})
+			require.NoError(t, err)
+
+			got := make(map[string][]string)
+			for _, result := range dbManager.SavedResults {
+				got[strings.TrimPrefix(result.URL, server.URL)] = result.MatchingTerms
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	cm, _ := newTestCrawlManager(t)
+	err := cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+		CrawlSiteID: "site",
+		StartURL:    server.URL,
+		SearchTerms: []string{"hôpital"},
+		Language:    "de",
+	})
+	assert.ErrorIs(t, err, language.ErrUnsupported)
+}
+
+// newFeedServer serves a site whose robots.txt lists a sitemap index of a
+// news sitemap, and whose home page links to an RSS feed and no articles.
+func newFeedServer() *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, "User-agent: *\nAllow: /\nSitemap: %s/sitemap-index.xml\n", server.URL)
+	})
+	mux.HandleFunc("/sitemap-index.xml", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%s/news-sitemap.xml</loc></sitemap>
+</sitemapindex>`, server.URL)
+	})
+	mux.HandleFunc("/news-sitemap.xml", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:news="http://www.google.com/schemas/sitemap-news/0.9">
+  <url>
+    <loc>%[1]s/2024/03/05/a1</loc>
+    <lastmod>2024-03-06T08:00:00Z</lastmod>
+    <news:news>
+      <news:publication><news:name>Example</news:name><news:language>en</news:language></news:publication>
+      <news:publication_date>2024-03-05T12:00:00Z</news:publication_date>
+      <news:title>Flood warning issued for downtown</news:title>
+    </news:news>
+  </url>
+  <url><loc>%[1]s/2024/03/05/a2</loc></url>
+  <url><loc>https://elsewhere.example.com/flood</loc></url>
+</urlset>`, server.URL)
+	})
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+  <title>Example</title>
+  <item>
+    <title>Flooding closes the highway</title>
+    <link>%[1]s/2024/03/04/b1</link>
+    <pubDate>Mon, 04 Mar 2024 09:30:00 GMT</pubDate>
+  </item>
+  <item><title>Weather</title><link>%[1]s/2024/03/05/a1</link></item>
+</channel></rss>`, server.URL)
+	})
+
+	var mu sync.Mutex
+	visited := make(map[string]bool)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		mu.Lock()
+		visited[r.URL.Path] = true
+		mu.Unlock()
+		if r.URL.Path == "/" {
+			fmt.Fprint(w, `<html><head>
+				<link rel="alternate" type="application/rss+xml" href="/feed.xml">
+			</head><body><p>home</p></body></html>`)
+			return
+		}
+		if r.URL.Path == "/2024/03/05/a2" {
+			fmt.Fprint(w, `<html><body><a href="/flood-maps">Flood maps</a></body></html>`)
+			return
+		}
+		fmt.Fprint(w, "<html><body><p>article</p></body></html>")
+	})
+
+	return server
+}
+
+func TestCrawlWithOptionsDiscoverFeeds(t *testing.T) {
+	server := newFeedServer()
+	defer server.Close()
+
+	cm, dbManager := newTestCrawlManager(t)
+	err := cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+		CrawlSiteID:          "site",
+		StartURL:             server.URL,
+		SearchTerms:          []string{"flood"},
+		DiscoverFeeds:        true,
+		MaxDepth:             2,
+		DelayBetweenRequests: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	got := make(map[string]models.PageData)
+	for _, result := range dbManager.SavedResults {
+		got[strings.TrimPrefix(result.URL, server.URL)] = result
+	}
+	require.Len(t, got, 3)
+
+	// Matched by the title and dates of the news sitemap
+	article := got["/2024/03/05/a1"]
+	assert.Equal(t, "Flood warning issued for downtown", article.Title)
+	require.NotNil(t, article.PublishedTime)
+	assert.Equal(t, time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC), article.PublishedTime.UTC())
+	require.NotNil(t, article.ModifiedTime)
+	assert.Equal(t, time.Date(2024, 3, 6, 8, 0, 0, 0, time.UTC), article.ModifiedTime.UTC())
+
+	// Matched by the title of the RSS feed
+	item := got["/2024/03/04/b1"]
+	assert.Equal(t, "Flooding closes the highway", item.Title)
+	require.NotNil(t, item.PublishedTime)
+	assert.Equal(t, time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC), item.PublishedTime.UTC())
+
+	// Found by crawling a page of the sitemap, which did not match
+	assert.Contains(t, got, "/flood-maps")
+}
+
+func TestCrawlWithOptionsFeeds(t *testing.T) {
+	server := newFeedServer()
+	defer server.Close()
+
+	cm, dbManager := newTestCrawlManager(t)
+	err := cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+		CrawlSiteID:          "site",
+		StartURL:             server.URL,
+		SearchTerms:          []string{"flood"},
+		Feeds:                []string{server.URL + "/feed.xml"},
+		MaxDepth:             1,
+		DelayBetweenRequests: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	var got []string
+	for _, result := range dbManager.SavedResults {
+		got = append(got, strings.TrimPrefix(result.URL, server.URL))
+	}
+	assert.Equal(t, []string{"/2024/03/04/b1"}, got)
+}
+
+func TestCrawlWithOptionsFeedsOffDomain(t *testing.T) {
+	var offDomainHits atomic.Int32
+	offDomain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		offDomainHits.Add(1)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"></urlset>`)
+	}))
+	defer offDomain.Close()
+
+	server := newFeedServer()
+	defer server.Close()
+
+	// The sitemap index of the site lists its news sitemap and a sitemap on
+	// a domain the crawl does not allow
+	index := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%s/news-sitemap.xml</loc></sitemap>
+  <sitemap><loc>%s/sitemap.xml</loc></sitemap>
+</sitemapindex>`, server.URL, offDomain.URL)
+	}))
+	defer index.Close()
+
+	cm, dbManager := newTestCrawlManager(t)
+	err := cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+		CrawlSiteID:          "site",
+		StartURL:             server.URL,
+		SearchTerms:          []string{"flood"},
+		Feeds:                []string{index.URL + "/sitemap-index.xml"},
+		MaxDepth:             1,
+		DelayBetweenRequests: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.Zero(t, offDomainHits.Load())
+
+	var got []string
+	for _, result := range dbManager.SavedResults {
+		got = append(got, strings.TrimPrefix(result.URL, server.URL))
+	}
+	assert.Contains(t, got, "/2024/03/05/a1")
+}
+
+func TestCrawlWithOptionsIncremental(t *testing.T) {
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	articles := []string{"/news/flood-warning"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", http.NotFound)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		links := append([]string{}, articles...)
+		mu.Unlock()
+
+		// The home page links to a section listing the articles
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><body><a href="/about">About</a><a href="/news">News</a></body></html>`)
+		case "/news":
+			fmt.Fprint(w, "<html><body>")
+			for _, link := range links {
+				fmt.Fprintf(w, `<a href="%s">Flood</a>`, link)
+			}
+			fmt.Fprint(w, "</body></html>")
+		default:
+			fmt.Fprint(w, "<html><body><p>article</p></body></html>")
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cm, dbManager := newTestCrawlManager(t)
+	crawl := func(incremental bool) string {
+		t.Helper()
+		require.NoError(t, cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+			CrawlSiteID:          "site",
+			StartURL:             server.URL,
+			SearchTerms:          []string{"flood"},
+			Incremental:          incremental,
+			MaxDepth:             2,
+			DelayBetweenRequests: time.Millisecond,
+		}))
+		runs, err := dbManager.ListCrawlRuns(context.Background(), "site")
+		require.NoError(t, err)
+		return runs[0].ID
+	}
+
+	first := crawl(false)
+
+	seen, err := dbManager.GetSeenURLs(context.Background(), "site")
+	require.NoError(t, err)
+	assert.Contains(t, seen, server.URL+"/about")
+	assert.Contains(t, seen, server.URL+"/news")
+	assert.Contains(t, seen, server.URL+"/news/flood-warning")
+
+	mu.Lock()
+	articles = append(articles, "/news/flood-maps")
+	mu.Unlock()
+
+	second := crawl(true)
+
+	// The pages seen before are crawled again, to find the new article on
+	// the section, but only the new article is reported
+	mu.Lock()
+	assert.Equal(t, map[string]int{"/": 2, "/about": 2, "/news": 2, "/news/flood-warning": 2, "/news/flood-maps": 1}, hits)
+	mu.Unlock()
+
+	got := make(map[string]string)
+	for _, result := range dbManager.SavedResults {
+		got[strings.TrimPrefix(result.URL, server.URL)] = result.RunID
+	}
+	assert.Equal(t, map[string]string{"/news/flood-warning": first, "/news/flood-maps": second}, got)
+}
@@ -0,0 +1,164 @@
+package crawler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly"
+	"github.com/jonesrussell/page-prowler/models"
+)
+
+// publishedTimeSelectors are the elements pages use for their publish date,
+// most specific first.
+var publishedTimeSelectors = []struct {
+	selector string
+	attr     string
+}{
+	{`meta[property="article:published_time"]`, "content"},
+	{`meta[itemprop="datePublished"]`, "content"},
+	{`meta[name="pubdate"]`, "content"},
+	{`meta[name="publishdate"]`, "content"},
+	{`meta[name="date"]`, "content"},
+	{`time[itemprop="datePublished"]`, "datetime"},
+	{`article time[datetime]`, "datetime"},
+}
+
+// publishedTimeLayouts are the date formats found in publish dates.
+var publishedTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// extractMetadata reads the title, description, image, canonical URL and
+// publish date of a page from its html element.
+func extractMetadata(e *colly.HTMLElement) models.PageMetadata {
+	metadata := models.PageMetadata{
+		Title:        firstNonEmpty(e.ChildAttr(`meta[property="og:title"]`, "content"), e.ChildText("head > title")),
+		Description:  firstNonEmpty(e.ChildAttr(`meta[name="description"]`, "content"), e.ChildAttr(`meta[property="og:description"]`, "content")),
+		Image:        e.Request.AbsoluteURL(e.ChildAttr(`meta[property="og:image"]`, "content")),
+		CanonicalURL: e.Request.AbsoluteURL(firstNonEmpty(e.ChildAttr(`link[rel="canonical"]`, "href"), e.ChildAttr(`meta[property="og:url"]`, "content"))),
+	}
+
+	for _, s := range publishedTimeSelectors {
+		if published, ok := parsePublishedTime(e.ChildAttr(s.selector, s.attr)); ok {
+			metadata.PublishedTime = &published
+			break
+		}
+	}
+
+	return metadata
+}
+
+// parsePublishedTime parses a publish date in any of publishedTimeLayouts.
+func parsePublishedTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range publishedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// handlePage records the metadata of a fetched page, and saves it to the
+// result of the page if it was matched before it was fetched. With
+// MatchContent, it also matches the search terms against the page, unless
+// an earlier crawl saw it.
+func (s *crawlSession) handlePage(e *colly.HTMLElement) {
+	pageURL := e.Request.URL.String()
+	pageData := models.PageData{URL: pageURL, PageMetadata: extractMetadata(e)}
+
+	s.mu.Lock()
+	s.metadata[pageURL] = pageData.PageMetadata
+	matched := s.pending[pageURL]
+	delete(s.pending, pageURL)
+	s.mu.Unlock()
+
+	if s.options.MatchContent && !s.known(pageURL) && s.matchPageContent(e, &pageData) {
+		matched = true
+	}
+	if !matched {
+		return
+	}
+	s.stamp(&pageData)
+
+	if err := s.manager.DBManager.SaveResults(s.ctx, []models.PageData{pageData}, s.options.CrawlSiteID); err != nil {
+		s.manager.Logger.Error("Error saving page: ", err)
+	}
+}
+
+// attachMetadata adds the metadata of the page of a match if it has been
+// fetched already, otherwise it marks the page to be fetched. Incremental
+// crawls leave the pages seen before, whose metadata is saved already.
+func (s *crawlSession) attachMetadata(pageData *models.PageData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if metadata, ok := s.metadata[pageData.URL]; ok {
+		pageData.PageMetadata.Merge(metadata)
+		return
+	}
+	if !s.known(pageData.URL) {
+		s.pending[pageData.URL] = true
+	}
+}
+
+// fetchPendingMetadata fetches the matched pages that the crawl did not
+// visit, because they were beyond MaxDepth or it stopped early, to record
+// their metadata.
+func (s *crawlSession) fetchPendingMetadata() {
+	s.mu.Lock()
+	urls := make([]string, 0, len(s.pending))
+	for pageURL := range s.pending {
+		urls = append(urls, pageURL)
+	}
+	s.mu.Unlock()
+
+	if len(urls) == 0 {
+		return
+	}
+	s.manager.Logger.Debug("[fetchPendingMetadata]", "pages", len(urls))
+
+	// The clone shares the HTTP client and the limit rules of the crawl, so
+	// the rule for any domain limits the requests for pages on other sites
+	// too, and their redirects are followed to the domains of the crawl only.
+	collector := s.collector.GetCollector().Clone()
+	collector.AllowURLRevisit = true
+	collector.MaxDepth = 0
+
+	// The fetches count against the budget of the crawl like any other page
+	collector.OnRequest(func(r *colly.Request) {
+		if s.ctx.Err() != nil || !s.reservePage() {
+			r.Abort()
+		}
+	})
+	collector.OnHTML("html", s.handlePage)
+
+	for _, pageURL := range urls {
+		if s.ctx.Err() != nil || s.budgetExhausted() {
+			return
+		}
+		if err := collector.Visit(pageURL); err != nil {
+			s.manager.Logger.Debug("[fetchPendingMetadata]", "url", pageURL, "error", err)
+		}
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const articlePath = "/news/flood-warning-downtown"
+
+// newArticleServer serves a home page linking to an article with metadata.
+// It counts the requests for the article.
+func newArticleServer(articleRequests *atomic.Int32) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", http.NotFound)
+	mux.HandleFunc(articlePath, func(w http.ResponseWriter, _ *http.Request) {
+		articleRequests.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head>
+			<title>Flood warning | Example News</title>
+			<meta property="og:title" content="Flood warning issued for downtown">
+			<meta name="description" content="Residents are asked to avoid the river banks.">
+			<meta property="og:image" content="/images/flood.jpg">
+			<link rel="canonical" href="/news/2024/flood-warning">
+			<meta property="article:published_time" content="2024-03-05T08:30:00-05:00">
+		</head><body><p>article</p></body></html>`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<html><body><a href="%s">Flood warning</a></body></html>`, articlePath)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestCrawlRecordsMatchMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxDepth int
+	}{
+		// The article is crawled, its metadata is reused
+		{name: "page crawled", maxDepth: 2},
+		// The article is beyond MaxDepth, it is fetched after the crawl
+		{name: "page beyond max depth", maxDepth: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var articleRequests atomic.Int32
+			server := newArticleServer(&articleRequests)
+			defer server.Close()
+
+			cm, dbManager := newTestCrawlManager(t)
+			require.NoError(t, cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+				CrawlSiteID:          "site",
+				StartURL:             server.URL,
+				SearchTerms:          []string{"flood"},
+				MaxDepth:             tt.maxDepth,
+				DelayBetweenRequests: time.Millisecond,
+			}))
+
+			assert.Equal(t, int32(1), articleRequests.Load())
+
+			result, err := dbManager.GetResult(context.Background(), "site", server.URL+articlePath)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"flood"}, result.MatchingTerms)
+			assert.Equal(t, "Flood warning issued for downtown", result.Title)
+			assert.Equal(t, "Residents are asked to avoid the river banks.", result.Description)
+			assert.Equal(t, server.URL+"/images/flood.jpg", result.Image)
+			assert.Equal(t, server.URL+"/news/2024/flood-warning", result.CanonicalURL)
+			require.NotNil(t, result.PublishedTime)
+			assert.Equal(t, time.Date(2024, 3, 5, 13, 30, 0, 0, time.UTC), *result.PublishedTime)
+		})
+	}
+}
+
+func TestCrawlLimitsMetadataOfOtherSites(t *testing.T) {
+	var articleRequests atomic.Int32
+	articles := newArticleServer(&articleRequests)
+	defer articles.Close()
+	articlesURL := strings.Replace(articles.URL, "127.0.0.1", "localhost", 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", http.NotFound)
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, `<a href="%s%s?page=%d">Flood warning</a>`, articlesURL, articlePath, i)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	const delay = 100 * time.Millisecond
+	cm, dbManager := newTestCrawlManager(t)
+	start := time.Now()
+	require.NoError(t, cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+		CrawlSiteID:           "site",
+		StartURL:              server.URL,
+		SearchTerms:           []string{"flood"},
+		MaxConcurrentRequests: 1,
+		DelayBetweenRequests:  delay,
+	}))
+
+	// The articles are on another site, only fetched for their metadata, and
+	// wait for the delay of the crawl as its pages do
+	assert.Equal(t, int32(3), articleRequests.Load())
+	assert.GreaterOrEqual(t, time.Since(start), 4*delay)
+
+	result, err := dbManager.GetResult(context.Background(), "site", articlesURL+articlePath+"?page=0")
+	require.NoError(t, err)
+	assert.Equal(t, "Flood warning issued for downtown", result.Title)
+}
+
+func TestParsePublishedTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{value: "2024-03-05T08:30:00Z", want: time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC), ok: true},
+		{value: "2024-03-05T08:30:00-0500", want: time.Date(2024, 3, 5, 13, 30, 0, 0, time.UTC), ok: true},
+		{value: "2024-03-05T08:30:00", want: time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC), ok: true},
+		{value: " 2024-03-05 ", want: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), ok: true},
+		{value: "Tue, 05 Mar 2024 08:30:00 +0000", want: time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC), ok: true},
+		{value: "", ok: false},
+		{value: "last Tuesday", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parsePublishedTime(tt.value)
+			assert.Equal(t, tt.ok, ok)
+			assert.True(t, tt.want.Equal(got), "got %v, want %v", got, tt.want)
+		})
+	}
+}
@@ -1,16 +1,145 @@
 package crawler
 
-import "time"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
 
-// CrawlOptions represents the configuration for a crawl.
+	"github.com/jonesrussell/page-prowler/internal/language"
+	"github.com/jonesrussell/page-prowler/internal/matcher"
+	"github.com/jonesrussell/page-prowler/internal/termmatcher"
+	"github.com/jonesrussell/page-prowler/utils"
+)
+
+// CrawlOptions represents the configuration for a crawl. The run of the crawl
+// keeps them, for it to be resumed with the same options.
 type CrawlOptions struct {
-	CrawlSiteID           string
-	Debug                 bool
-	DelayBetweenRequests  time.Duration
-	MaxConcurrentRequests int
-	MaxDepth              int
-	SearchTerms           []string
-	StartURL              string
+	// AllowedDomains are domains the crawl visits next to the hosts of its
+	// start URLs, such as "cp24.com", or "*.cp24.com" for the domain and all
+	// its subdomains, see ValidateAllowedDomain.
+	AllowedDomains []string `json:"allowed_domains,omitempty"`
+	CrawlSiteID    string   `json:"crawl_site_id"`
+	Debug          bool     `json:"debug"`
+	// DelayBetweenRequests is the pause between requests to a domain, none if
+	// it is 0. DefaultDelay is the default of the CLI and of crawl tasks.
+	DelayBetweenRequests time.Duration `json:"delay_between_requests"`
+	// DiscoverFeeds adds the sitemaps robots.txt lists, or /sitemap.xml, and
+	// the feeds the start pages link to, to Feeds.
+	DiscoverFeeds bool          `json:"discover_feeds,omitempty"`
+	DomainLimits  []DomainLimit `json:"domain_limits,omitempty"`
+	// Exclude and Include are rules, see ParseURLRule, for the links the
+	// crawl follows and reports: none matching an Exclude rule and, if there
+	// are Include rules, only those matching one of them.
+	Exclude []string `json:"exclude,omitempty"`
+	// Feeds are the URLs of sitemaps, sitemap indexes, news sitemaps, or RSS
+	// or Atom feeds whose pages the crawl starts from too.
+	Feeds   []string `json:"feeds,omitempty"`
+	Include []string `json:"include,omitempty"`
+	// Incremental crawls follow the links earlier crawls of the site saw, to
+	// find new links, but only report the new ones. Every crawl records the
+	// URLs it saw, and when it found each result.
+	Incremental bool `json:"incremental,omitempty"`
+	// Language is the language of the site, such as "fr", whose stopwords are
+	// removed and stemmer used. Without it the language of each page is taken
+	// from its lang attribute or detected from its content.
+	Language string `json:"language,omitempty"`
+	// MaxConcurrentRequests is the number of requests run at once per domain,
+	// at least 1. DefaultParallelism is the default of the CLI and of crawl
+	// tasks.
+	MaxConcurrentRequests int `json:"max_concurrent_requests"`
+	// Matchers names registered topic matchers, such as "drug" or "mining",
+	// that match links on their own, reporting the topic as the matching term.
+	Matchers []string `json:"matchers,omitempty"`
+	// MatchContent also matches the search terms against the title and the
+	// main content of every page the crawl visits, not only its links.
+	MatchContent bool          `json:"match_content,omitempty"`
+	MaxDepth     int           `json:"max_depth"`
+	MaxDuration  time.Duration `json:"max_duration,omitempty"` // 0 for no limit
+	MaxPages     int           `json:"max_pages,omitempty"`    // 0 for no limit
+	// Query is a boolean query, see termmatcher.Query, matched instead of
+	// SearchTerms. Without either, the query saved for the site is used.
+	Query       string        `json:"query,omitempty"`
+	RandomDelay time.Duration `json:"random_delay"`
+	SearchTerms []string      `json:"search_terms"`
+	// SimilarityMetric and SimilarityThreshold choose how terms that are not
+	// exactly in the content are compared, see matcher.NewSimilarity.
+	SimilarityMetric    string  `json:"similarity_metric,omitempty"`
+	SimilarityThreshold float64 `json:"similarity_threshold,omitempty"`
+	StartURL            string  `json:"start_url"`
+	// StartURLs are more URLs the crawl starts from, next to StartURL.
+	StartURLs []string `json:"start_urls,omitempty"`
+}
+
+// ErrNoCrawlSiteID is returned for crawls without a CrawlSiteID.
+var ErrNoCrawlSiteID = errors.New("no crawl site ID")
+
+// Validate checks the options of a crawl before it is started or queued: its
+// CrawlSiteID, start URLs and feeds, AllowedDomains, Include and Exclude
+// rules, Query, similarity, Matchers, Language and limits.
+func (o *CrawlOptions) Validate() error {
+	if o.CrawlSiteID == "" {
+		return ErrNoCrawlSiteID
+	}
+	if len(o.Seeds()) == 0 {
+		return ErrNoStartURL
+	}
+	for _, seed := range o.Seeds() {
+		if _, err := utils.GetHostFromURL(seed); err != nil {
+			return fmt.Errorf("invalid start URL %q: %v", seed, err)
+		}
+	}
+	for _, feed := range o.Feeds {
+		if _, err := utils.GetHostFromURL(feed); err != nil {
+			return fmt.Errorf("invalid feed %q: %v", feed, err)
+		}
+	}
+	if err := ValidateAllowedDomains(o.AllowedDomains); err != nil {
+		return err
+	}
+	if _, err := o.urlRules(); err != nil {
+		return err
+	}
+
+	if o.Query != "" {
+		if _, err := termmatcher.ParseQuery(o.Query); err != nil {
+			return err
+		}
+	}
+	if _, err := matcher.NewSimilarity(o.SimilarityMetric, o.SimilarityThreshold); err != nil {
+		return err
+	}
+	if err := matcher.DefaultRegistry.Validate(o.Matchers); err != nil {
+		return err
+	}
+	if o.Language != "" {
+		if _, err := language.Parse(o.Language); err != nil {
+			return err
+		}
+	}
+
+	if o.MaxDepth < 0 {
+		return fmt.Errorf("max depth must not be negative")
+	}
+	if o.MaxPages < 0 {
+		return fmt.Errorf("max pages must not be negative")
+	}
+	if o.MaxConcurrentRequests < 1 {
+		return fmt.Errorf("max concurrent requests must be at least 1")
+	}
+	if o.DelayBetweenRequests < 0 {
+		return fmt.Errorf("delay between requests must not be negative")
+	}
+
+	return nil
+}
+
+// crawlContext returns the context of a crawl, bounded by MaxDuration.
+func (o *CrawlOptions) crawlContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.MaxDuration > 0 {
+		return context.WithTimeout(ctx, o.MaxDuration)
+	}
+	return context.WithCancel(ctx)
 }
 
 // SetOptions Method to set options
@@ -23,3 +152,80 @@ func (cm *CrawlManager) SetOptions(options *CrawlOptions) error {
 func (cm *CrawlManager) GetOptions() *CrawlOptions {
 	return cm.Options
 }
+
+// ErrNoSearchTerms is returned when a crawl has no search terms, no query, no
+// matchers, and no query is saved for its site.
+var ErrNoSearchTerms = errors.New("no search terms or query")
+
+// searchQuery returns the query a crawl matches links with: its Query or,
+// when it has no SearchTerms either, the query saved for its site. It
+// returns nil when the crawl matches its SearchTerms.
+func (cm *CrawlManager) searchQuery(ctx context.Context, options *CrawlOptions) (*termmatcher.Query, error) {
+	text := options.Query
+	if text == "" {
+		if len(options.SearchTerms) > 0 {
+			return nil, nil
+		}
+
+		saved, err := cm.DBManager.GetQuery(ctx, options.CrawlSiteID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get saved query: %v", err)
+		}
+		if saved == "" {
+			if len(options.Matchers) > 0 {
+				return nil, nil
+			}
+			return nil, ErrNoSearchTerms
+		}
+		text = saved
+	}
+
+	return termmatcher.ParseQuery(text)
+}
+
+// termMatcher returns the TermMatcher of the manager, configured for the
+// crawl with ConfigureTermMatcher.
+func (cm *CrawlManager) termMatcher(options *CrawlOptions) (*termmatcher.TermMatcher, error) {
+	return ConfigureTermMatcher(cm.TermMatcher, cm.Matchers, options)
+}
+
+// ConfigureTermMatcher returns a copy of termMatcher that compares terms with
+// the similarity metric and threshold of options if they are set, processes
+// text in the language of options if it is set, and matches with the
+// matchers of registry that options enables. The matchers use the threshold
+// of options only if it is set.
+func ConfigureTermMatcher(termMatcher *termmatcher.TermMatcher, registry *matcher.Registry, options *CrawlOptions) (*termmatcher.TermMatcher, error) {
+	if options.Language != "" {
+		lang, err := language.Parse(options.Language)
+		if err != nil {
+			return nil, err
+		}
+		termMatcher = termMatcher.WithLanguage(lang)
+	}
+
+	var similarity matcher.Similarity
+	if options.SimilarityMetric != "" || options.SimilarityThreshold != 0 {
+		var err error
+		similarity, err = matcher.NewSimilarity(options.SimilarityMetric, options.SimilarityThreshold)
+		if err != nil {
+			return nil, err
+		}
+		termMatcher = termMatcher.WithSimilarity(similarity)
+	}
+
+	if len(options.Matchers) > 0 {
+		if registry == nil {
+			registry = matcher.DefaultRegistry
+		}
+		// Matchers keep their own threshold unless the crawl sets one, the
+		// default of the metric is not tuned to them
+		similarity.Threshold = options.SimilarityThreshold
+		matchers, err := registry.New(options.Matchers, similarity)
+		if err != nil {
+			return nil, err
+		}
+		termMatcher = termMatcher.WithMatchers(matchers)
+	}
+
+	return termMatcher, nil
+}
@@ -0,0 +1,57 @@
+package crawler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrawlOptionsValidate(t *testing.T) {
+	valid := func() *CrawlOptions {
+		return &CrawlOptions{
+			CrawlSiteID:           "site",
+			StartURL:              "https://www.cp24.com",
+			StartURLs:             []string{"https://www.cp24.com/news"},
+			AllowedDomains:        []string{"*.cp24.com"},
+			Include:               []string{"/news/"},
+			Exclude:               []string{`re:[?&]page=\d+`},
+			Feeds:                 []string{"https://www.cp24.com/sitemap.xml"},
+			Query:                 "fire OR flood",
+			Matchers:              []string{"drug"},
+			Language:              "fr",
+			MaxConcurrentRequests: 2,
+		}
+	}
+	assert.NoError(t, valid().Validate())
+
+	tests := []struct {
+		name   string
+		modify func(o *CrawlOptions)
+	}{
+		{name: "site ID", modify: func(o *CrawlOptions) { o.CrawlSiteID = "" }},
+		{name: "no start URL", modify: func(o *CrawlOptions) { o.StartURL, o.StartURLs = "", nil }},
+		{name: "start URL", modify: func(o *CrawlOptions) { o.StartURLs = []string{"not a url"} }},
+		{name: "feed", modify: func(o *CrawlOptions) { o.Feeds = []string{"/sitemap.xml"} }},
+		{name: "allowed domain", modify: func(o *CrawlOptions) { o.AllowedDomains = []string{"https://cp24.com"} }},
+		{name: "include rule", modify: func(o *CrawlOptions) { o.Include = []string{""} }},
+		{name: "exclude rule", modify: func(o *CrawlOptions) { o.Exclude = []string{"re:("} }},
+		{name: "query", modify: func(o *CrawlOptions) { o.Query = "(fire" }},
+		{name: "similarity metric", modify: func(o *CrawlOptions) { o.SimilarityMetric = "soundex" }},
+		{name: "similarity threshold", modify: func(o *CrawlOptions) { o.SimilarityThreshold = 1.5 }},
+		{name: "matcher", modify: func(o *CrawlOptions) { o.Matchers = []string{"sports"} }},
+		{name: "language", modify: func(o *CrawlOptions) { o.Language = "xx" }},
+		{name: "max depth", modify: func(o *CrawlOptions) { o.MaxDepth = -1 }},
+		{name: "max pages", modify: func(o *CrawlOptions) { o.MaxPages = -1 }},
+		{name: "max concurrent requests", modify: func(o *CrawlOptions) { o.MaxConcurrentRequests = 0 }},
+		{name: "delay between requests", modify: func(o *CrawlOptions) { o.DelayBetweenRequests = -time.Second }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := valid()
+			tt.modify(options)
+			assert.Error(t, options.Validate())
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package crawler
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// regexpRulePrefix marks the URL rules that are regular expressions.
+const regexpRulePrefix = "re:"
+
+// ParseURLRule compiles a rule of Include or Exclude. A rule matches URLs
+// that contain it, "*" matching any characters, such as "/news/" or
+// "/20*/video/". A rule starting with "re:" is a regular expression matched
+// against the URL instead, such as `re:[?&]page=\d+`.
+func ParseURLRule(rule string) (*regexp.Regexp, error) {
+	if rule == "" {
+		return nil, fmt.Errorf("empty URL rule")
+	}
+
+	if expr, ok := strings.CutPrefix(rule, regexpRulePrefix); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL rule %q: %v", rule, err)
+		}
+		return re, nil
+	}
+
+	parts := strings.Split(rule, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile(strings.Join(parts, ".*")), nil
+}
+
+// ParseURLRules compiles each of the given rules.
+func ParseURLRules(rules []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(rules))
+	for _, rule := range rules {
+		re, err := ParseURLRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// urlRules decides which URLs a crawl follows and reports. A URL matching an
+// exclude rule is dropped; otherwise, if there are include rules, it has to
+// match one of them. The start URLs are always crawled.
+type urlRules struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// urlRules compiles the Include and Exclude rules of the crawl.
+func (o *CrawlOptions) urlRules() (urlRules, error) {
+	include, err := ParseURLRules(o.Include)
+	if err != nil {
+		return urlRules{}, err
+	}
+	exclude, err := ParseURLRules(o.Exclude)
+	if err != nil {
+		return urlRules{}, err
+	}
+	return urlRules{include: include, exclude: exclude}, nil
+}
+
+// allows reports whether the rules let the crawl follow and report rawURL.
+func (r urlRules) allows(rawURL string) bool {
+	for _, re := range r.exclude {
+		if re.MatchString(rawURL) {
+			return false
+		}
+	}
+	if len(r.include) == 0 {
+		return true
+	}
+	for _, re := range r.include {
+		if re.MatchString(rawURL) {
+			return true
+		}
+	}
+	return false
+}
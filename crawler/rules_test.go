@@ -0,0 +1,112 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURLRule(t *testing.T) {
+	tests := []struct {
+		rule  string
+		url   string
+		match bool
+	}{
+		{rule: "/news/", url: "https://www.cp24.com/news/2024/flood", match: true},
+		{rule: "/news/", url: "https://www.cp24.com/newsletter", match: false},
+		{rule: "?page=", url: "https://www.cp24.com/news?page=2", match: true},
+		{rule: "?page=", url: "https://www.cp24.com/news/page", match: false},
+		{rule: "/20*/video/", url: "https://www.cp24.com/2024/03/video/flood", match: true},
+		{rule: "/20*/video/", url: "https://www.cp24.com/video/2024", match: false},
+		{rule: `re:[?&]page=\d+$`, url: "https://www.cp24.com/news?sort=new&page=12", match: true},
+		{rule: `re:[?&]page=\d+$`, url: "https://www.cp24.com/news?page=last", match: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule+" "+tt.url, func(t *testing.T) {
+			re, err := ParseURLRule(tt.rule)
+			require.NoError(t, err)
+			assert.Equal(t, tt.match, re.MatchString(tt.url))
+		})
+	}
+
+	_, err := ParseURLRule("")
+	assert.Error(t, err)
+	_, err = ParseURLRule("re:(news")
+	assert.Error(t, err)
+}
+
+func TestURLRulesAllows(t *testing.T) {
+	rules, err := (&CrawlOptions{
+		Include: []string{"/news/", "/local/"},
+		Exclude: []string{"/video/", "?page="},
+	}).urlRules()
+	require.NoError(t, err)
+
+	assert.True(t, rules.allows("https://www.cp24.com/news/flood"))
+	assert.True(t, rules.allows("https://www.cp24.com/local/flood"))
+	assert.False(t, rules.allows("https://www.cp24.com/sports/flood"))
+	assert.False(t, rules.allows("https://www.cp24.com/news/video/flood"))
+	assert.False(t, rules.allows("https://www.cp24.com/news/?page=2"))
+
+	// Without include rules everything not excluded is allowed
+	rules, err = (&CrawlOptions{Exclude: []string{"/video/"}}).urlRules()
+	require.NoError(t, err)
+	assert.True(t, rules.allows("https://www.cp24.com/sports/flood"))
+	assert.False(t, rules.allows("https://www.cp24.com/video/flood"))
+}
+
+func TestCrawlWithOptionsURLRules(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", http.NotFound)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><body>
+				<a href="/news/flood-warning">Flood warning</a>
+				<a href="/news/video/flood-footage">Flood footage</a>
+				<a href="/news/?page=2">More flood news</a>
+				<a href="/sports/flood-delays-game">Flood delays game</a>
+			</body></html>`)
+		case "/news/flood-warning":
+			fmt.Fprint(w, `<html><body><a href="/news/flood-maps">Flood maps</a></body></html>`)
+		case "/sports/flood-delays-game":
+			fmt.Fprint(w, `<html><body><a href="/news/flood-insurance">Flood insurance</a></body></html>`)
+		default:
+			fmt.Fprint(w, "<html><body><p>article</p></body></html>")
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cm, dbManager := newTestCrawlManager(t)
+	err := cm.CrawlWithOptions(context.Background(), &CrawlOptions{
+		CrawlSiteID:          "site",
+		StartURL:             server.URL,
+		SearchTerms:          []string{"flood"},
+		Include:              []string{"/news/"},
+		Exclude:              []string{"/video/", "?page="},
+		MaxDepth:             3,
+		DelayBetweenRequests: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	var got []string
+	for _, result := range dbManager.SavedResults {
+		got = append(got, strings.TrimPrefix(result.URL, server.URL))
+	}
+	// The start URL is crawled although it does not match the include rule,
+	// the sports page is neither reported nor followed
+	assert.ElementsMatch(t, []string{"/news/flood-warning", "/news/flood-maps"}, got)
+
+	_, err = (&CrawlOptions{Include: []string{"re:("}}).urlRules()
+	assert.Error(t, err)
+}
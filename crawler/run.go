@@ -0,0 +1,70 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jonesrussell/page-prowler/models"
+)
+
+// newCrawlRun creates the run record for a crawl that is about to start.
+func newCrawlRun(options *CrawlOptions) *models.CrawlRun {
+	run := &models.CrawlRun{
+		ID:        uuid.NewString(),
+		SiteID:    options.CrawlSiteID,
+		Status:    models.CrawlRunStatusRunning,
+		StartedAt: time.Now().UTC(),
+	}
+
+	if data, err := json.Marshal(options); err == nil {
+		run.Options = data
+	}
+
+	return run
+}
+
+// startCrawlRun records the start of a crawl.
+func (cm *CrawlManager) startCrawlRun(run *models.CrawlRun) {
+	if err := cm.DBManager.SaveCrawlRun(context.Background(), run); err != nil {
+		cm.Logger.Error("Error saving crawl run", err)
+	}
+
+	cm.Logger.Info("[Crawl] Started crawl run", "id", run.ID, "siteid", run.SiteID)
+}
+
+// finishCrawlRun records the outcome and statistics of a crawl. session is nil
+// if the crawl failed before its session was created.
+func (cm *CrawlManager) finishCrawlRun(run *models.CrawlRun, session *crawlSession, crawlErr error) {
+	ended := time.Now().UTC()
+	run.EndedAt = &ended
+
+	if session != nil {
+		session.finishCheckpoint(crawlErr)
+
+		linkStats := session.stats.LinkStats
+		run.TotalPages = linkStats.GetTotalPages()
+		run.TotalLinks = linkStats.GetTotalLinks()
+		run.MatchedLinks = linkStats.GetMatchedLinks()
+		run.NotMatchedLinks = linkStats.GetNotMatchedLinks()
+	}
+
+	switch {
+	case crawlErr == nil:
+		run.Status = models.CrawlRunStatusCompleted
+	case errors.Is(crawlErr, context.Canceled), errors.Is(crawlErr, context.DeadlineExceeded):
+		run.Status = models.CrawlRunStatusCancelled
+		run.Error = crawlErr.Error()
+	default:
+		run.Status = models.CrawlRunStatusFailed
+		run.Error = crawlErr.Error()
+	}
+
+	if err := cm.DBManager.SaveCrawlRun(context.Background(), run); err != nil {
+		cm.Logger.Error("Error saving crawl run", err)
+	}
+
+	cm.Logger.Info("[Crawl] Finished crawl run", "id", run.ID, "status", run.Status, "duration", run.Duration().String())
+}
@@ -0,0 +1,227 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gocolly/colly"
+	"github.com/jonesrussell/page-prowler/models"
+)
+
+// crawlSession holds everything that belongs to a single crawl run.
+type crawlSession struct {
+	ctx       context.Context
+	manager   *CrawlManager
+	options   *CrawlOptions
+	run       *models.CrawlRun
+	collector *CollectorWrapper
+	storage   CrawlStorage
+	stats     *StatsManager
+	domains   []string // the domain patterns the crawl may visit
+	rules     urlRules // the URLs the crawl follows and reports
+
+	*LinkMatcher // matches the links and pages of the crawl
+
+	mu        sync.Mutex // guards results, metadata, pending, languages, frontier, started and crawled
+	results   *Results
+	metadata  map[string]models.PageMetadata // metadata of the pages fetched, by URL
+	pending   map[string]bool                // matched URLs whose page has not been fetched yet
+	languages map[string]string              // languages detected in the pages fetched, by URL
+	history   map[string]time.Time           // URLs seen by earlier crawls, loaded by incremental crawls
+	frontier  map[string]int                 // pages queued or being crawled, with their depth
+	started   map[uint32]string              // URLs of the requests being made, by request ID
+	crawled   map[string]bool                // pages the crawl is done with
+
+	checkpointMu sync.Mutex              // orders the checkpoints of the crawl
+	resumed      *models.CrawlCheckpoint // the checkpoint a resumed crawl started from
+
+	requests     atomic.Int64 // requests started, counted against MaxPages
+	exhausted    atomic.Bool  // set once a request is dropped for MaxPages
+	queued       atomic.Bool  // set once the queue is filled, from when checkpoints are saved
+	crawledPages atomic.Int64 // pages crawled, counted for checkpoints
+}
+
+// newCrawlSession creates a session with a fresh collector and storage.
+func (cm *CrawlManager) newCrawlSession(ctx context.Context, run *models.CrawlRun, options *CrawlOptions) (*crawlSession, error) {
+	links, err := cm.newLinkMatcher(nil, options)
+	if err != nil {
+		return nil, err
+	}
+
+	collector, crawlStorage, err := cm.CollectorFactory(run.ID, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collector: %v", err)
+	}
+
+	// Set the storage for the collector
+	if err := collector.GetCollector().SetStorage(crawlStorage); err != nil {
+		return nil, fmt.Errorf("failed to set storage: %v", err)
+	}
+
+	return &crawlSession{
+		ctx:         ctx,
+		manager:     cm,
+		options:     options,
+		run:         run,
+		collector:   collector,
+		storage:     crawlStorage,
+		stats:       NewStatsManager(),
+		LinkMatcher: links,
+		results:     NewResults(),
+		metadata:    make(map[string]models.PageMetadata),
+		pending:     make(map[string]bool),
+		languages:   make(map[string]string),
+		frontier:    make(map[string]int),
+		started:     make(map[uint32]string),
+		crawled:     make(map[string]bool),
+	}, nil
+}
+
+// close removes the data the crawl stored and releases its connections.
+func (s *crawlSession) close() {
+	logger := s.manager.Logger
+
+	if err := s.storage.Clear(); err != nil {
+		logger.Error("Error clearing crawl storage", err)
+	}
+	if err := s.storage.Close(); err != nil {
+		logger.Error("Error closing crawl storage", err)
+	}
+}
+
+func (s *crawlSession) configureCollector(allowedDomains []string) error {
+	logger := s.manager.Logger
+	logger.Debug("[configureCollector]", "maxDepth", s.options.MaxDepth)
+
+	// Get the underlying colly.Collector from the CollectorWrapper
+	collector := s.collector.GetCollector()
+
+	rules, err := s.options.urlRules()
+	if err != nil {
+		return err
+	}
+	s.rules = rules
+
+	// colly only allows exact hosts, the crawl checks the links it follows
+	// and their redirects against its patterns itself
+	s.domains = allowedDomains
+	collector.AllowedDomains = nil
+	collector.RedirectHandler = s.checkRedirect
+	logger.Info("Allowed domains: ", "whitelist", allowedDomains)
+
+	collector.AllowURLRevisit = false
+	collector.Async = false
+	collector.IgnoreRobotsTxt = false
+	collector.MaxDepth = s.options.MaxDepth
+
+	if err := collector.Limits(s.options.limitRules()); err != nil {
+		return err
+	}
+
+	// Abort requests in flight when the crawl is cancelled
+	s.collector.WithTransport(&contextTransport{ctx: s.ctx, base: s.collector.Transport()})
+
+	// Drop the requests that are still queued once the crawl is cancelled or
+	// out of budget, so that the queue drains quickly. They are kept for the
+	// checkpoint, to be made when the crawl is resumed.
+	collector.OnRequest(func(r *colly.Request) {
+		s.trackRequest(r)
+		if s.ctx.Err() != nil || !s.reservePage() {
+			r.Abort()
+		}
+	})
+
+	collector.OnHTML("html", s.handlePage)
+
+	collector.OnHTML("a[href]", func(e *colly.HTMLElement) {
+		href, err := getHref(e)
+		if err != nil {
+			return
+		}
+		if href == "" {
+			return
+		}
+		if !s.rules.allows(href) {
+			return
+		}
+
+		s.stats.LinkStats.IncrementTotalLinks()
+
+		// Use TermMatcher to find matching terms in the URL and anchor text.
+		// Incremental crawls do not report the links earlier crawls saw, but
+		// still follow them to find the links that are new.
+		links := s.pageLinkMatcher(e)
+		matchingTerms := links.linkMatchingTerms(href, e.Text)
+		if len(matchingTerms) > 0 && !s.known(href) {
+			pageData := createPageData(href)
+			pageData.MatchLocations = links.linkMatchLocations(href, e.Text)
+			pageData.Relevance = links.linkRelevance(href, e.Text, matchingTerms)
+			err := s.handleMatchingTerms(e.Request.URL.String(), pageData, matchingTerms)
+			if err != nil {
+				return
+			}
+		} else {
+			s.updateStats(matchingTerms)
+		}
+
+		if !s.allowed(href) {
+			return
+		}
+		// colly only knows the depth of resumed pages from the queue
+		if s.options.MaxDepth > 0 && s.depth(e.Request) >= s.options.MaxDepth {
+			return
+		}
+		err = e.Request.Visit(href)
+		if err != nil {
+			return
+		}
+	})
+
+	collector.OnScraped(func(r *colly.Response) {
+		s.stats.LinkStats.IncrementTotalPages()
+		s.finishRequest(r.Request)
+		s.pageCrawled()
+	})
+
+	collector.OnError(func(r *colly.Response, err error) {
+		s.manager.Logger.Debug("[OnError]", "url", r.Request.URL.String(), "status", r.StatusCode, "error", err)
+		// Requests cut short by a cancelled crawl are made again on resume
+		if s.ctx.Err() == nil {
+			s.finishRequest(r.Request)
+		}
+	})
+
+	return nil
+}
+
+// reservePage counts a request against MaxPages and reports whether it may
+// go ahead.
+func (s *crawlSession) reservePage() bool {
+	if s.options.MaxPages <= 0 {
+		return true
+	}
+	if s.requests.Add(1) > int64(s.options.MaxPages) {
+		s.exhausted.Store(true)
+		return false
+	}
+	return true
+}
+
+// budgetExhausted reports whether requests were dropped because of MaxPages.
+func (s *crawlSession) budgetExhausted() bool {
+	return s.exhausted.Load()
+}
+
+// contextTransport cancels requests when ctx is done.
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
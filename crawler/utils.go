@@ -1,15 +1,13 @@
 package crawler
 
 import (
-	"context"
 	"errors"
-	"strings"
 
 	"github.com/gocolly/colly"
 	"github.com/jonesrussell/page-prowler/models"
 )
 
-func (cm *CrawlManager) getHref(e *colly.HTMLElement) (string, error) {
+func getHref(e *colly.HTMLElement) (string, error) {
 	href := e.Request.AbsoluteURL(e.Attr("href"))
 	if href == "" {
 		return "", errors.New("found anchor element with no href attribute")
@@ -17,43 +15,75 @@ func (cm *CrawlManager) getHref(e *colly.HTMLElement) (string, error) {
 	return href, nil
 }
 
-func (cm *CrawlManager) createPageData(href string) models.PageData {
+func createPageData(href string) models.PageData {
 	return models.PageData{
 		URL: href,
 	}
 }
 
-func (cm *CrawlManager) handleMatchingTerms(options *CrawlOptions, currentURL string, pageData models.PageData, matchingTerms []string) error {
-	cm.Logger.Debug("handleMatchingTerms called")
+func (s *crawlSession) handleMatchingTerms(currentURL string, pageData models.PageData, matchingTerms []string) error {
+	logger := s.manager.Logger
+	logger.Debug("handleMatchingTerms called")
 
-	// Calculate the similarity score
-	similarityScore := cm.TermMatcher.CompareTerms(currentURL, strings.Join(matchingTerms, " "))
+	pageData.MatchingTerms = matchingTerms
+	s.attachMetadata(&pageData)
+	s.stamp(&pageData)
 
-	pageData.UpdatePageData(matchingTerms, similarityScore) // Update the PageData with the similarity score
+	// Append the PageData directly to the session results
+	s.mu.Lock()
+	s.results.Pages = append(s.results.Pages, pageData)
+	s.mu.Unlock()
 
-	// Append the PageData directly to Results.Pages
-	cm.Results.Pages = append(cm.Results.Pages, pageData)
+	s.updateStats(matchingTerms)
 
-	cm.UpdateStats(options, matchingTerms)
+	// Save the result
+	key := s.options.CrawlSiteID
 
-	// Save the result to Redis
-	ctx := context.Background() // Or use a context from your application
-	key := options.CrawlSiteID
-
-	err := cm.DBManager.SaveResults(ctx, []models.PageData{pageData}, key)
+	err := s.manager.DBManager.SaveResults(s.ctx, []models.PageData{pageData}, key)
 
 	if err != nil {
-		cm.Logger.Error("Error saving result to Redis: ", err)
+		logger.Error("Error saving result: ", err)
 		return err
 	}
 
 	return nil
 }
 
-func (cm *CrawlManager) UpdateStats(_ *CrawlOptions, matchingTerms []string) {
+// matchPageContent matches the search terms against the title and the main
+// content of a page and adds what it finds to pageData. It reports whether
+// any term matched.
+func (s *crawlSession) matchPageContent(e *colly.HTMLElement, pageData *models.PageData) bool {
+	body := extractContent(e.DOM)
+	links := s.pageLinkMatcher(e)
+
+	matchingTerms := links.textMatchingTerms(pageData.Title + "\n" + body)
+	if len(matchingTerms) == 0 {
+		return false
+	}
+
+	pageData.Merge(models.PageData{
+		MatchingTerms: matchingTerms,
+		Relevance: links.termMatcher.Relevance(map[string]string{
+			models.MatchLocationTitle: pageData.Title,
+			models.MatchLocationBody:  body,
+		}, matchingTerms),
+		MatchLocations: links.matchLocations(
+			[]string{models.MatchLocationTitle, models.MatchLocationBody},
+			[]string{pageData.Title, body},
+		),
+	})
+
+	s.mu.Lock()
+	s.results.Pages = append(s.results.Pages, *pageData)
+	s.mu.Unlock()
+
+	return true
+}
+
+func (s *crawlSession) updateStats(matchingTerms []string) {
 	if len(matchingTerms) > 0 {
-		cm.StatsManager.LinkStats.IncrementMatchedLinks()
+		s.stats.LinkStats.IncrementMatchedLinks()
 	} else {
-		cm.StatsManager.LinkStats.IncrementNotMatchedLinks()
+		s.stats.LinkStats.IncrementNotMatchedLinks()
 	}
 }
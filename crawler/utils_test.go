@@ -53,32 +53,34 @@ func TestHandleMatchingTerms(t *testing.T) {
 	mockMatcher := &MockMatcher{}
 	termMatcher := termmatcher.NewTermMatcher(logger, []matcher.Matcher{mockMatcher})
 
-	cm := NewCrawlManager(logger, dbManager, nil, nil, nil)
-	cm.TermMatcher = termMatcher
-	cm.initializeStatsManager()
-
 	// Define the input parameters
 	options := &CrawlOptions{CrawlSiteID: "test_crawl"}
+
+	cm := NewCrawlManager(logger, dbManager, nil, options)
+	cm.TermMatcher = termMatcher
+	session, err := cm.newCrawlSession(context.Background(), newCrawlRun(options), options)
+	assert.NoError(t, err)
 	currentURL := "https://www.example.com/the-cat-has-been-abducted"
 	pageData := models.PageData{URL: currentURL}
 	matchingTerms := []string{"abduct"}
 
 	// Call the function
-	err := cm.handleMatchingTerms(options, currentURL, pageData, matchingTerms)
+	err = session.handleMatchingTerms(currentURL, pageData, matchingTerms)
 
 	// Assert that there was no error
 	assert.NoError(t, err)
 
 	// Define the expected PageData
 	expectedPageData := models.PageData{
-		URL:             currentURL,
-		MatchingTerms:   matchingTerms,
-		SimilarityScore: 1, // Update this to the expected similarity score
+		URL:           currentURL,
+		MatchingTerms: matchingTerms,
+		FirstSeen:     &session.run.StartedAt,
+		RunID:         session.run.ID,
 	}
 
 	// Assert that the result was saved to Redis
 	ctx := context.Background()
-	savedResults, err := dbManager.GetResultsFromRedis(ctx, options.CrawlSiteID)
+	savedResults, err := dbManager.GetResults(ctx, options.CrawlSiteID)
 
 	// Additional assertions
 	assert.NoError(t, err)
@@ -0,0 +1,399 @@
+package dbmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jonesrussell/loggo"
+	"github.com/jonesrussell/page-prowler/internal/stats"
+	"github.com/jonesrussell/page-prowler/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Top level buckets of the bolt database. Results and crawl run IDs are kept
+// in a nested bucket per site. Results are keyed by URL. The URLs seen by the
+// crawls of a site are keyed by URL too, with the time they were first seen.
+// Checkpoints are keyed by the ID of their crawl run.
+var (
+	resultsBucket     = []byte("pages")
+	statsBucket       = []byte("stats")
+	crawlRunsBucket   = []byte("crawlruns")
+	siteRunsBucket    = []byte("siteruns")
+	queriesBucket     = []byte("queries")
+	seenBucket        = []byte("seen")
+	checkpointsBucket = []byte("checkpoints")
+)
+
+// BoltManager stores everything in a single bolt database file, so crawls can
+// run on one machine without a Redis server.
+type BoltManager struct {
+	db     *bolt.DB
+	logger loggo.LoggerInterface
+}
+
+var _ DatabaseManagerInterface = &BoltManager{}
+
+// NewBoltManager opens, or creates, the bolt database at path.
+func NewBoltManager(path string, logger loggo.LoggerInterface) (*BoltManager, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening bolt database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{resultsBucket, statsBucket, crawlRunsBucket, siteRunsBucket, queriesBucket, seenBucket, checkpointsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("error creating bolt buckets: %w", err)
+	}
+
+	return &BoltManager{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// SaveResults upserts each result, keyed by its URL, into the results of a
+// site.
+func (bm *BoltManager) SaveResults(_ context.Context, results []models.PageData, siteid string) error {
+	if siteid == "" {
+		return fmt.Errorf("key is not set")
+	}
+
+	bm.logger.Debug("Bolt", "siteid", siteid)
+	bm.logger.Debug("Bolt", "results", results)
+
+	return bm.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(resultsBucket).CreateBucketIfNotExists([]byte(siteid))
+		if err != nil {
+			return fmt.Errorf("error creating results bucket: %w", err)
+		}
+
+		for _, result := range results {
+			if existing := bucket.Get([]byte(result.URL)); existing != nil {
+				var saved models.PageData
+				if err := json.Unmarshal(existing, &saved); err != nil {
+					return fmt.Errorf("error unmarshaling PageData: %w", err)
+				}
+				saved.Merge(result)
+				result = saved
+			}
+
+			data, err := json.Marshal(result)
+			if err != nil {
+				return fmt.Errorf("error marshaling PageData: %w", err)
+			}
+			if err := bucket.Put([]byte(result.URL), data); err != nil {
+				return fmt.Errorf("error adding data to bolt: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ClearResults removes the results of a site.
+func (bm *BoltManager) ClearResults(_ context.Context, siteid string) error {
+	return bm.db.Update(func(tx *bolt.Tx) error {
+		err := tx.Bucket(resultsBucket).DeleteBucket([]byte(siteid))
+		if err == bolt.ErrBucketNotFound {
+			return nil
+		}
+		return err
+	})
+}
+
+// GetLinks returns the results of a site as JSON encoded PageData.
+func (bm *BoltManager) GetLinks(ctx context.Context, siteid string) ([]string, error) {
+	results, err := bm.GetResults(ctx, siteid)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalResults(results)
+}
+
+// GetResults returns the results of a site, ordered by URL.
+func (bm *BoltManager) GetResults(_ context.Context, siteid string) ([]models.PageData, error) {
+	results := []models.PageData{}
+	err := bm.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(resultsBucket).Bucket([]byte(siteid))
+		if bucket == nil {
+			return nil
+		}
+
+		// bolt iterates over the keys, the URLs, in order
+		return bucket.ForEach(func(_, v []byte) error {
+			var pageData models.PageData
+			if err := json.Unmarshal(v, &pageData); err != nil {
+				return fmt.Errorf("error unmarshaling PageData: %w", err)
+			}
+			results = append(results, pageData)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetResult returns the result of a site for a URL.
+func (bm *BoltManager) GetResult(_ context.Context, siteid, url string) (*models.PageData, error) {
+	var data []byte
+	err := bm.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(resultsBucket).Bucket([]byte(siteid))
+		if bucket == nil {
+			return nil
+		}
+		if v := bucket.Get([]byte(url)); v != nil {
+			data = append([]byte{}, v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, ErrResultNotFound
+	}
+
+	pageData := &models.PageData{}
+	if err := json.Unmarshal(data, pageData); err != nil {
+		return nil, fmt.Errorf("error unmarshaling PageData: %w", err)
+	}
+
+	return pageData, nil
+}
+
+// SaveStats saves the statistics of the last crawl of a site.
+func (bm *BoltManager) SaveStats(_ context.Context, siteid string, linkStats *stats.Stats) error {
+	data, err := json.Marshal(linkStats)
+	if err != nil {
+		return fmt.Errorf("error marshaling Stats: %w", err)
+	}
+
+	return bm.put(statsBucket, siteid, data)
+}
+
+// GetStats returns the statistics of the last crawl of a site.
+// It returns nil and no error if no crawl has been recorded.
+func (bm *BoltManager) GetStats(_ context.Context, siteid string) (*stats.Stats, error) {
+	data, err := bm.get(statsBucket, siteid)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	linkStats := &stats.Stats{}
+	if err := json.Unmarshal(data, linkStats); err != nil {
+		return nil, fmt.Errorf("error unmarshaling Stats: %w", err)
+	}
+
+	return linkStats, nil
+}
+
+// SaveQuery saves the search query of a site. An empty query deletes it.
+func (bm *BoltManager) SaveQuery(_ context.Context, siteid, query string) error {
+	if query == "" {
+		return bm.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(queriesBucket).Delete([]byte(siteid))
+		})
+	}
+
+	return bm.put(queriesBucket, siteid, []byte(query))
+}
+
+// GetQuery returns the search query of a site, or "" if it has none.
+func (bm *BoltManager) GetQuery(_ context.Context, siteid string) (string, error) {
+	query, err := bm.get(queriesBucket, siteid)
+	return string(query), err
+}
+
+// SaveSeenURLs adds the URLs not seen before to the URLs seen by the crawls
+// of a site.
+func (bm *BoltManager) SaveSeenURLs(_ context.Context, siteid string, urls []string, seen time.Time) error {
+	if siteid == "" {
+		return fmt.Errorf("key is not set")
+	}
+
+	value := []byte(seen.Format(time.RFC3339Nano))
+	return bm.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(seenBucket).CreateBucketIfNotExists([]byte(siteid))
+		if err != nil {
+			return fmt.Errorf("error creating seen bucket: %w", err)
+		}
+
+		for _, url := range urls {
+			if url == "" || bucket.Get([]byte(url)) != nil {
+				continue
+			}
+			if err := bucket.Put([]byte(url), value); err != nil {
+				return fmt.Errorf("error adding seen URL to bolt: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetSeenURLs returns the URLs the crawls of a site saw, with the time each
+// was first seen.
+func (bm *BoltManager) GetSeenURLs(_ context.Context, siteid string) (map[string]time.Time, error) {
+	fields := make(map[string]string)
+	err := bm.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(seenBucket).Bucket([]byte(siteid))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			fields[string(k)] = string(v)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSeenURLs(fields)
+}
+
+// SaveCrawlRun creates or updates a crawl run and indexes it under its site ID.
+func (bm *BoltManager) SaveCrawlRun(_ context.Context, run *models.CrawlRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("error marshaling CrawlRun: %w", err)
+	}
+
+	return bm.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(crawlRunsBucket).Put([]byte(run.ID), data); err != nil {
+			return fmt.Errorf("error adding crawl run to bolt: %w", err)
+		}
+
+		siteRuns, err := tx.Bucket(siteRunsBucket).CreateBucketIfNotExists([]byte(run.SiteID))
+		if err != nil {
+			return fmt.Errorf("error indexing crawl run in bolt: %w", err)
+		}
+		return siteRuns.Put([]byte(run.ID), []byte{})
+	})
+}
+
+// GetCrawlRun returns the crawl run with the given ID.
+func (bm *BoltManager) GetCrawlRun(_ context.Context, id string) (*models.CrawlRun, error) {
+	data, err := bm.get(crawlRunsBucket, id)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, ErrCrawlRunNotFound
+	}
+
+	run := &models.CrawlRun{}
+	if err := json.Unmarshal(data, run); err != nil {
+		return nil, fmt.Errorf("error unmarshaling CrawlRun: %w", err)
+	}
+
+	return run, nil
+}
+
+// ListCrawlRuns returns the crawl runs of a site, most recent first.
+func (bm *BoltManager) ListCrawlRuns(_ context.Context, siteid string) ([]models.CrawlRun, error) {
+	var runs []models.CrawlRun
+	err := bm.db.View(func(tx *bolt.Tx) error {
+		siteRuns := tx.Bucket(siteRunsBucket).Bucket([]byte(siteid))
+		if siteRuns == nil {
+			return nil
+		}
+
+		crawlRuns := tx.Bucket(crawlRunsBucket)
+		return siteRuns.ForEach(func(id, _ []byte) error {
+			data := crawlRuns.Get(id)
+			if data == nil {
+				return nil
+			}
+
+			var run models.CrawlRun
+			if err := json.Unmarshal(data, &run); err != nil {
+				return fmt.Errorf("error unmarshaling CrawlRun: %w", err)
+			}
+			runs = append(runs, run)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortCrawlRuns(runs)
+
+	return runs, nil
+}
+
+// SaveCheckpoint creates or replaces the checkpoint of a crawl run.
+func (bm *BoltManager) SaveCheckpoint(_ context.Context, checkpoint *models.CrawlCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("error marshaling CrawlCheckpoint: %w", err)
+	}
+
+	return bm.put(checkpointsBucket, checkpoint.RunID, data)
+}
+
+// GetCheckpoint returns the checkpoint of a crawl run.
+func (bm *BoltManager) GetCheckpoint(_ context.Context, runID string) (*models.CrawlCheckpoint, error) {
+	data, err := bm.get(checkpointsBucket, runID)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, ErrCheckpointNotFound
+	}
+
+	checkpoint := &models.CrawlCheckpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("error unmarshaling CrawlCheckpoint: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+// DeleteCheckpoint deletes the checkpoint of a crawl run.
+func (bm *BoltManager) DeleteCheckpoint(_ context.Context, runID string) error {
+	return bm.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(checkpointsBucket).Delete([]byte(runID))
+	})
+}
+
+// Close closes the bolt database.
+func (bm *BoltManager) Close() error {
+	return bm.db.Close()
+}
+
+func (bm *BoltManager) put(bucket []byte, key string, value []byte) error {
+	if key == "" {
+		return fmt.Errorf("key is not set")
+	}
+
+	return bm.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(key), value)
+	})
+}
+
+// get returns a copy of the value of key, or nil if it does not exist.
+func (bm *BoltManager) get(bucket []byte, key string) ([]byte, error) {
+	var value []byte
+	err := bm.db.View(func(tx *bolt.Tx) error {
+		if data := tx.Bucket(bucket).Get([]byte(key)); data != nil {
+			value = append([]byte{}, data...)
+		}
+		return nil
+	})
+	return value, err
+}
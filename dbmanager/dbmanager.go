@@ -3,25 +3,94 @@ package dbmanager
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/jonesrussell/loggo"
 	"github.com/jonesrussell/page-prowler/internal/prowlredis"
+	"github.com/jonesrussell/page-prowler/internal/stats"
 	"github.com/jonesrussell/page-prowler/models"
 )
 
+// Storage backends
+const (
+	BackendRedis = "redis"
+	BackendBolt  = "bolt"
+)
+
+// DatabaseManagerInterface stores the results, statistics and runs of crawls.
+// Results and statistics are stored per site ID, with one result per URL.
 type DatabaseManagerInterface interface {
-	SaveResults(ctx context.Context, results []models.PageData, key string) error
-	ClearRedisSet(ctx context.Context, key string) error
-	GetLinksFromRedis(ctx context.Context, key string) ([]string, error)
+	// SaveResults inserts the results, or merges them into the saved results
+	// for the same URLs.
+	SaveResults(ctx context.Context, results []models.PageData, siteid string) error
+	ClearResults(ctx context.Context, siteid string) error
+	// GetLinks returns the results of a site as JSON encoded PageData.
+	GetLinks(ctx context.Context, siteid string) ([]string, error)
+	GetResults(ctx context.Context, siteid string) ([]models.PageData, error)
+	GetResult(ctx context.Context, siteid, url string) (*models.PageData, error)
+	SaveStats(ctx context.Context, siteid string, linkStats *stats.Stats) error
+	GetStats(ctx context.Context, siteid string) (*stats.Stats, error)
+	SaveCrawlRun(ctx context.Context, run *models.CrawlRun) error
+	GetCrawlRun(ctx context.Context, id string) (*models.CrawlRun, error)
+	ListCrawlRuns(ctx context.Context, siteid string) ([]models.CrawlRun, error)
+	// SaveCheckpoint creates or replaces the checkpoint of a crawl run.
+	SaveCheckpoint(ctx context.Context, checkpoint *models.CrawlCheckpoint) error
+	// GetCheckpoint returns the checkpoint of a crawl run, or
+	// ErrCheckpointNotFound if it has none.
+	GetCheckpoint(ctx context.Context, runID string) (*models.CrawlCheckpoint, error)
+	// DeleteCheckpoint deletes the checkpoint of a crawl run, if it has one.
+	DeleteCheckpoint(ctx context.Context, runID string) error
+	// SaveQuery saves the search query of a site. An empty query deletes it.
+	SaveQuery(ctx context.Context, siteid, query string) error
+	// GetQuery returns the search query of a site, or "" if it has none.
+	GetQuery(ctx context.Context, siteid string) (string, error)
+	// SaveSeenURLs records that a crawl of a site saw the URLs at seen. URLs
+	// seen before keep the time they were first seen.
+	SaveSeenURLs(ctx context.Context, siteid string, urls []string, seen time.Time) error
+	// GetSeenURLs returns the URLs the crawls of a site saw, with the time
+	// each was first seen.
+	GetSeenURLs(ctx context.Context, siteid string) (map[string]time.Time, error)
+	Close() error
+}
+
+// ResultsMigrator is implemented by the backends that can convert results
+// saved in the old format, a set of JSON encoded PageData per site, to one
+// record per URL.
+type ResultsMigrator interface {
+	// MigrateResults converts the old results of a site and returns how many
+	// it converted.
+	MigrateResults(ctx context.Context, siteid string) (int, error)
+}
+
+// RedisBackend is implemented by the managers that store their data in Redis.
+// The task queue used by the api and worker commands shares that Redis.
+type RedisBackend interface {
 	RedisOptions() prowlredis.Options
 }
 
+// ErrCrawlRunNotFound is returned when a crawl run does not exist.
+var ErrCrawlRunNotFound = errors.New("crawl run not found")
+
+// ErrCheckpointNotFound is returned when a crawl run has no checkpoint.
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// ErrResultNotFound is returned when a site has no result for a URL.
+var ErrResultNotFound = errors.New("result not found")
+
 type RedisManager struct {
 	client prowlredis.ClientInterface
 	logger loggo.LoggerInterface
 }
 
+var (
+	_ DatabaseManagerInterface = &RedisManager{}
+	_ ResultsMigrator          = &RedisManager{}
+	_ RedisBackend             = &RedisManager{}
+)
+
 func NewRedisManager(client prowlredis.ClientInterface, logger loggo.LoggerInterface) *RedisManager {
 	return &RedisManager{
 		client: client,
@@ -29,39 +98,378 @@ func NewRedisManager(client prowlredis.ClientInterface, logger loggo.LoggerInter
 	}
 }
 
+// SaveResults upserts each result into a hash per URL and indexes the URL in
+// the set of results of the site. Results are merged in a transaction, so
+// that crawls of the same site saving the same URL do not lose updates.
 func (rm *RedisManager) SaveResults(ctx context.Context, results []models.PageData, key string) error {
 	// Log the key and the results at the top
 	rm.logger.Debug("Redis", "key", key)
 	rm.logger.Debug("Redis", "results", results)
 
+	if key == "" {
+		return fmt.Errorf("key is not set")
+	}
+
 	for _, result := range results {
-		data, err := json.Marshal(result)
+		err := rm.client.HUpdate(ctx, resultKey(key, result.URL), func(fields map[string]string) ([]interface{}, error) {
+			merged := result
+			if len(fields) > 0 {
+				existing, err := pageDataFromFields(fields)
+				if err != nil {
+					return nil, err
+				}
+				existing.Merge(result)
+				merged = *existing
+			}
+			return pageDataFields(merged)
+		})
 		if err != nil {
-			return fmt.Errorf("error marshaling PageData: %w", err)
+			return fmt.Errorf("error adding data to Redis: %w", err)
 		}
-		str := string(data)
 
-		err = rm.client.SAdd(ctx, key, str)
+		if err := rm.client.SAdd(ctx, resultsKey(key), result.URL); err != nil {
+			return fmt.Errorf("error indexing data in Redis: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ClearResults deletes the results of a site and their index.
+func (rm *RedisManager) ClearResults(ctx context.Context, key string) error {
+	urls, err := rm.client.SMembers(ctx, resultsKey(key))
+	if err != nil {
+		return fmt.Errorf("error getting data from Redis: %w", err)
+	}
+
+	keys := []string{resultsKey(key)}
+	for _, url := range urls {
+		keys = append(keys, resultKey(key, url))
+	}
+
+	return rm.client.Del(ctx, keys...)
+}
+
+// GetLinks returns the results of a site as JSON encoded PageData.
+func (rm *RedisManager) GetLinks(ctx context.Context, key string) ([]string, error) {
+	results, err := rm.GetResults(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalResults(results)
+}
+
+// GetResults returns the results of a site, ordered by URL.
+func (rm *RedisManager) GetResults(ctx context.Context, key string) ([]models.PageData, error) {
+	urls, err := rm.client.SMembers(ctx, resultsKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("error getting data from Redis: %w", err)
+	}
+
+	results := make([]models.PageData, 0, len(urls))
+	for _, url := range urls {
+		result, err := rm.GetResult(ctx, key, url)
+		if errors.Is(err, ErrResultNotFound) {
+			continue
+		}
 		if err != nil {
-			return fmt.Errorf("error adding data to Redis: %w", err)
+			return nil, err
+		}
+		results = append(results, *result)
+	}
+
+	sortResults(results)
+
+	return results, nil
+}
+
+// GetResult returns the result of a site for a URL.
+func (rm *RedisManager) GetResult(ctx context.Context, key, url string) (*models.PageData, error) {
+	fields, err := rm.client.HGetAll(ctx, resultKey(key, url))
+	if err != nil {
+		return nil, fmt.Errorf("error getting data from Redis: %w", err)
+	}
+	if len(fields) == 0 {
+		return nil, ErrResultNotFound
+	}
+
+	return pageDataFromFields(fields)
+}
+
+// MigrateResults converts the set of JSON encoded PageData that used to be
+// stored under the site ID into one hash per URL, then deletes the set.
+func (rm *RedisManager) MigrateResults(ctx context.Context, key string) (int, error) {
+	members, err := rm.client.SMembers(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("error getting data from Redis: %w", err)
+	}
+
+	results, err := unmarshalResults(members)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := rm.SaveResults(ctx, results, key); err != nil {
+		return 0, err
+	}
+
+	if err := rm.client.Del(ctx, key); err != nil {
+		return 0, fmt.Errorf("error deleting data from Redis: %w", err)
+	}
+
+	return len(results), nil
+}
+
+func resultsKey(siteid string) string {
+	return "results:" + siteid
+}
+
+func resultKey(siteid, url string) string {
+	return "result:" + siteid + ":" + url
+}
+
+// SaveStats saves the statistics of the last crawl for the given key.
+func (rm *RedisManager) SaveStats(ctx context.Context, key string, linkStats *stats.Stats) error {
+	data, err := json.Marshal(linkStats)
+	if err != nil {
+		return fmt.Errorf("error marshaling Stats: %w", err)
+	}
+
+	if err := rm.client.Set(ctx, statsKey(key), data, 0); err != nil {
+		return fmt.Errorf("error adding stats to Redis: %w", err)
+	}
+
+	return nil
+}
+
+// GetStats returns the statistics of the last crawl for the given key.
+// It returns nil and no error if no crawl has been recorded.
+func (rm *RedisManager) GetStats(ctx context.Context, key string) (*stats.Stats, error) {
+	data, err := rm.client.Get(ctx, statsKey(key))
+	if errors.Is(err, prowlredis.ErrNil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting stats from Redis: %w", err)
+	}
+
+	linkStats := &stats.Stats{}
+	if err := json.Unmarshal([]byte(data), linkStats); err != nil {
+		return nil, fmt.Errorf("error unmarshaling Stats: %w", err)
+	}
+
+	return linkStats, nil
+}
+
+func statsKey(key string) string {
+	return key + ":stats"
+}
+
+// SaveQuery saves the search query of a site. An empty query deletes it.
+func (rm *RedisManager) SaveQuery(ctx context.Context, siteid, query string) error {
+	if siteid == "" {
+		return fmt.Errorf("key is not set")
+	}
+
+	if query == "" {
+		if err := rm.client.Del(ctx, queryKey(siteid)); err != nil {
+			return fmt.Errorf("error deleting query from Redis: %w", err)
+		}
+		return nil
+	}
+
+	if err := rm.client.Set(ctx, queryKey(siteid), query, 0); err != nil {
+		return fmt.Errorf("error adding query to Redis: %w", err)
+	}
+
+	return nil
+}
+
+// GetQuery returns the search query of a site, or "" if it has none.
+func (rm *RedisManager) GetQuery(ctx context.Context, siteid string) (string, error) {
+	query, err := rm.client.Get(ctx, queryKey(siteid))
+	if errors.Is(err, prowlredis.ErrNil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error getting query from Redis: %w", err)
+	}
+
+	return query, nil
+}
+
+func queryKey(siteid string) string {
+	return siteid + ":query"
+}
+
+// SaveSeenURLs adds the URLs not seen before to the hash of the URLs seen
+// by the crawls of a site. URLs are only set if they are not in the hash, so
+// the first crawl to save a URL keeps its time.
+func (rm *RedisManager) SaveSeenURLs(ctx context.Context, siteid string, urls []string, seen time.Time) error {
+	if siteid == "" {
+		return fmt.Errorf("key is not set")
+	}
+
+	values := make(map[string]interface{}, len(urls))
+	for _, url := range urls {
+		if url != "" {
+			values[url] = seen.Format(time.RFC3339Nano)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	if err := rm.client.HSetNX(ctx, seenKey(siteid), values); err != nil {
+		return fmt.Errorf("error adding seen URLs to Redis: %w", err)
+	}
+
+	return nil
+}
+
+// GetSeenURLs returns the URLs the crawls of a site saw, with the time each
+// was first seen.
+func (rm *RedisManager) GetSeenURLs(ctx context.Context, siteid string) (map[string]time.Time, error) {
+	fields, err := rm.client.HGetAll(ctx, seenKey(siteid))
+	if err != nil {
+		return nil, fmt.Errorf("error getting seen URLs from Redis: %w", err)
+	}
+
+	return parseSeenURLs(fields)
+}
+
+func seenKey(siteid string) string {
+	return "seen:" + siteid
+}
+
+// SaveCrawlRun creates or updates a crawl run and indexes it under its site ID.
+func (rm *RedisManager) SaveCrawlRun(ctx context.Context, run *models.CrawlRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("error marshaling CrawlRun: %w", err)
+	}
+
+	if err := rm.client.Set(ctx, crawlRunKey(run.ID), data, 0); err != nil {
+		return fmt.Errorf("error adding crawl run to Redis: %w", err)
+	}
+
+	if err := rm.client.SAdd(ctx, crawlRunsKey(run.SiteID), run.ID); err != nil {
+		return fmt.Errorf("error indexing crawl run in Redis: %w", err)
+	}
+
+	return nil
+}
+
+// GetCrawlRun returns the crawl run with the given ID.
+func (rm *RedisManager) GetCrawlRun(ctx context.Context, id string) (*models.CrawlRun, error) {
+	data, err := rm.client.Get(ctx, crawlRunKey(id))
+	if errors.Is(err, prowlredis.ErrNil) {
+		return nil, ErrCrawlRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting crawl run from Redis: %w", err)
+	}
+
+	run := &models.CrawlRun{}
+	if err := json.Unmarshal([]byte(data), run); err != nil {
+		return nil, fmt.Errorf("error unmarshaling CrawlRun: %w", err)
+	}
+
+	return run, nil
+}
+
+// ListCrawlRuns returns the crawl runs of a site, most recent first.
+func (rm *RedisManager) ListCrawlRuns(ctx context.Context, siteid string) ([]models.CrawlRun, error) {
+	ids, err := rm.client.SMembers(ctx, crawlRunsKey(siteid))
+	if err != nil {
+		return nil, fmt.Errorf("error getting crawl runs from Redis: %w", err)
+	}
+
+	runs := make([]models.CrawlRun, 0, len(ids))
+	for _, id := range ids {
+		run, err := rm.GetCrawlRun(ctx, id)
+		if errors.Is(err, ErrCrawlRunNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
 		}
+		runs = append(runs, *run)
+	}
+
+	sortCrawlRuns(runs)
+
+	return runs, nil
+}
+
+func crawlRunKey(id string) string {
+	return "crawlrun:" + id
+}
+
+func crawlRunsKey(siteid string) string {
+	return "crawlruns:" + siteid
+}
+
+// SaveCheckpoint creates or replaces the checkpoint of a crawl run.
+func (rm *RedisManager) SaveCheckpoint(ctx context.Context, checkpoint *models.CrawlCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("error marshaling CrawlCheckpoint: %w", err)
+	}
+
+	if err := rm.client.Set(ctx, checkpointKey(checkpoint.RunID), data, 0); err != nil {
+		return fmt.Errorf("error adding checkpoint to Redis: %w", err)
 	}
 
 	return nil
 }
 
-func (rm *RedisManager) ClearRedisSet(ctx context.Context, key string) error {
-	return rm.client.Del(ctx, key)
+// GetCheckpoint returns the checkpoint of a crawl run.
+func (rm *RedisManager) GetCheckpoint(ctx context.Context, runID string) (*models.CrawlCheckpoint, error) {
+	data, err := rm.client.Get(ctx, checkpointKey(runID))
+	if errors.Is(err, prowlredis.ErrNil) {
+		return nil, ErrCheckpointNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting checkpoint from Redis: %w", err)
+	}
+
+	checkpoint := &models.CrawlCheckpoint{}
+	if err := json.Unmarshal([]byte(data), checkpoint); err != nil {
+		return nil, fmt.Errorf("error unmarshaling CrawlCheckpoint: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+// DeleteCheckpoint deletes the checkpoint of a crawl run.
+func (rm *RedisManager) DeleteCheckpoint(ctx context.Context, runID string) error {
+	if err := rm.client.Del(ctx, checkpointKey(runID)); err != nil {
+		return fmt.Errorf("error deleting checkpoint from Redis: %w", err)
+	}
+	return nil
 }
 
-func (rm *RedisManager) GetLinksFromRedis(ctx context.Context, key string) ([]string, error) {
-	return rm.client.SMembers(ctx, key)
+func checkpointKey(runID string) string {
+	return "checkpoint:" + runID
+}
+
+func sortCrawlRuns(runs []models.CrawlRun) {
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
 }
 
 func (rm *RedisManager) RedisOptions() prowlredis.Options {
 	return *rm.client.Options()
 }
 
+// Close closes the Redis client.
+func (rm *RedisManager) Close() error {
+	return rm.client.Close()
+}
+
 type DBManager struct {
 	redisClient prowlredis.ClientInterface
 	logger      loggo.LoggerInterface
@@ -2,40 +2,190 @@ package dbmanager
 
 import (
 	"context"
+	"sync"
+	"time"
 
-	"github.com/jonesrussell/page-prowler/internal/prowlredis"
+	"github.com/jonesrussell/page-prowler/internal/stats"
 	"github.com/jonesrussell/page-prowler/models"
 )
 
 type MockDBManager struct {
+	mu sync.Mutex
 	// You can add more fields if needed
 	SavedResults []models.PageData
+	SavedStats   *stats.Stats
+	CrawlRuns    map[string]models.CrawlRun
+	Checkpoints  map[string]models.CrawlCheckpoint
+	Queries      map[string]string
+	SeenURLs     map[string]map[string]time.Time
 }
 
 func NewMockDBManager() *MockDBManager {
-	return &MockDBManager{}
+	return &MockDBManager{
+		CrawlRuns:   make(map[string]models.CrawlRun),
+		Checkpoints: make(map[string]models.CrawlCheckpoint),
+		Queries:     make(map[string]string),
+		SeenURLs:    make(map[string]map[string]time.Time),
+	}
 }
 func (m *MockDBManager) SaveResults(_ context.Context, results []models.PageData, _ string) error {
-	m.SavedResults = append(m.SavedResults, results...)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, result := range results {
+		if saved := m.find(result.URL); saved != nil {
+			saved.Merge(result)
+			continue
+		}
+		m.SavedResults = append(m.SavedResults, result)
+	}
 	return nil
 }
 
-func (m *MockDBManager) ClearRedisSet(_ context.Context, _ string) error {
+func (m *MockDBManager) find(url string) *models.PageData {
+	for i := range m.SavedResults {
+		if m.SavedResults[i].URL == url {
+			return &m.SavedResults[i]
+		}
+	}
+	return nil
+}
+
+func (m *MockDBManager) GetResult(_ context.Context, _ string, url string) (*models.PageData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	saved := m.find(url)
+	if saved == nil {
+		return nil, ErrResultNotFound
+	}
+	result := *saved
+	return &result, nil
+}
+
+func (m *MockDBManager) ClearResults(_ context.Context, _ string) error {
 	// Implement this if you use it in your tests
 	return nil
 }
 
-func (m *MockDBManager) GetLinksFromRedis(_ context.Context, _ string) ([]string, error) {
+func (m *MockDBManager) GetLinks(_ context.Context, _ string) ([]string, error) {
 	// Implement this if you use it in your tests
 	return nil, nil
 }
 
-func (m *MockDBManager) GetResultsFromRedis(_ context.Context, _ string) ([]models.PageData, error) {
+func (m *MockDBManager) GetResults(_ context.Context, _ string) ([]models.PageData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	// Return the saved results
 	return m.SavedResults, nil
 }
 
-func (m *MockDBManager) RedisOptions() prowlredis.Options {
-	// Implement this if you use it in your tests
-	return prowlredis.Options{}
+func (m *MockDBManager) SaveStats(_ context.Context, _ string, linkStats *stats.Stats) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.SavedStats = linkStats
+	return nil
+}
+
+func (m *MockDBManager) GetStats(_ context.Context, _ string) (*stats.Stats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.SavedStats, nil
+}
+
+func (m *MockDBManager) SaveCrawlRun(_ context.Context, run *models.CrawlRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.CrawlRuns[run.ID] = *run
+	return nil
+}
+
+func (m *MockDBManager) GetCrawlRun(_ context.Context, id string) (*models.CrawlRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run, ok := m.CrawlRuns[id]
+	if !ok {
+		return nil, ErrCrawlRunNotFound
+	}
+	return &run, nil
+}
+
+func (m *MockDBManager) ListCrawlRuns(_ context.Context, siteid string) ([]models.CrawlRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var runs []models.CrawlRun
+	for _, run := range m.CrawlRuns {
+		if run.SiteID == siteid {
+			runs = append(runs, run)
+		}
+	}
+	sortCrawlRuns(runs)
+	return runs, nil
+}
+
+func (m *MockDBManager) SaveCheckpoint(_ context.Context, checkpoint *models.CrawlCheckpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Checkpoints[checkpoint.RunID] = *checkpoint
+	return nil
+}
+
+func (m *MockDBManager) GetCheckpoint(_ context.Context, runID string) (*models.CrawlCheckpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	checkpoint, ok := m.Checkpoints[runID]
+	if !ok {
+		return nil, ErrCheckpointNotFound
+	}
+	return &checkpoint, nil
+}
+
+func (m *MockDBManager) DeleteCheckpoint(_ context.Context, runID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.Checkpoints, runID)
+	return nil
+}
+
+func (m *MockDBManager) SaveQuery(_ context.Context, siteid, query string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if query == "" {
+		delete(m.Queries, siteid)
+		return nil
+	}
+	m.Queries[siteid] = query
+	return nil
+}
+
+func (m *MockDBManager) GetQuery(_ context.Context, siteid string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Queries[siteid], nil
+}
+
+func (m *MockDBManager) SaveSeenURLs(_ context.Context, siteid string, urls []string, seen time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.SeenURLs[siteid] == nil {
+		m.SeenURLs[siteid] = make(map[string]time.Time)
+	}
+	for _, url := range urls {
+		if _, ok := m.SeenURLs[siteid][url]; !ok {
+			m.SeenURLs[siteid][url] = seen
+		}
+	}
+	return nil
+}
+
+func (m *MockDBManager) GetSeenURLs(_ context.Context, siteid string) (map[string]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seen := make(map[string]time.Time, len(m.SeenURLs[siteid]))
+	for url, t := range m.SeenURLs[siteid] {
+		seen[url] = t
+	}
+	return seen, nil
+}
+
+func (m *MockDBManager) Close() error {
+	return nil
 }
@@ -3,15 +3,23 @@ package dbmanager
 import (
 	"context"
 	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/golang/mock/gomock"
 	"github.com/jonesrussell/loggo"
 	"github.com/jonesrussell/page-prowler/internal/prowlredis"
+	"github.com/jonesrussell/page-prowler/internal/stats"
+	"github.com/jonesrussell/page-prowler/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestGetLinksFromRedis(t *testing.T) {
+func TestGetLinks(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -32,16 +40,25 @@ func TestGetLinksFromRedis(t *testing.T) {
 			key:  "key1",
 			setup: func() {
 				mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
-				mockClient.EXPECT().SMembers(ctx, "key1").Return([]string{"link1", "link2"}, nil).Times(1)
+				mockClient.EXPECT().SMembers(ctx, "results:key1").Return([]string{"https://example.com/b", "https://example.com/a"}, nil).Times(1)
+				mockClient.EXPECT().HGetAll(ctx, "result:key1:https://example.com/a").Return(map[string]string{
+					"url": "https://example.com/a", "matching_terms": `["fire"]`, "similarity_score": "0.5",
+				}, nil).Times(1)
+				mockClient.EXPECT().HGetAll(ctx, "result:key1:https://example.com/b").Return(map[string]string{
+					"url": "https://example.com/b", "matching_terms": `["flood"]`,
+				}, nil).Times(1)
+			},
+			want: []string{
+				`{"url":"https://example.com/a","matching_terms":["fire"],"similarity_score":0.5}`,
+				`{"url":"https://example.com/b","matching_terms":["flood"]}`,
 			},
-			want:    []string{"link1", "link2"},
 			wantErr: false,
 		},
 		{
 			name: "client SMembers error",
 			key:  "key1",
 			setup: func() {
-				mockClient.EXPECT().SMembers(ctx, "key1").Return(nil, errors.New("SMembers error")).Times(1)
+				mockClient.EXPECT().SMembers(ctx, "results:key1").Return(nil, errors.New("SMembers error")).Times(1)
 			},
 			want:    nil,
 			wantErr: true,
@@ -54,12 +71,377 @@ func TestGetLinksFromRedis(t *testing.T) {
 				tt.setup()
 			}
 
-			got, err := redisManager.GetLinksFromRedis(ctx, tt.key)
+			got, err := redisManager.GetLinks(ctx, tt.key)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("GetLinksFromRedis() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("GetLinks() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
+
+// forEachBackend runs test against every storage backend.
+func forEachBackend(t *testing.T, test func(t *testing.T, dm DatabaseManagerInterface)) {
+	backends := map[string]func(t *testing.T, logger loggo.LoggerInterface) DatabaseManagerInterface{
+		BackendRedis: func(t *testing.T, logger loggo.LoggerInterface) DatabaseManagerInterface {
+			mr := miniredis.RunT(t)
+			client, err := prowlredis.NewClient(context.Background(), &prowlredis.Options{Addr: mr.Addr()})
+			require.NoError(t, err)
+			return NewRedisManager(client, logger)
+		},
+		BackendBolt: func(t *testing.T, logger loggo.LoggerInterface) DatabaseManagerInterface {
+			dm, err := NewBoltManager(filepath.Join(t.TempDir(), "test.db"), logger)
+			require.NoError(t, err)
+			return dm
+		},
+	}
+
+	for name, newBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			logger := loggo.NewMockLoggerInterface(gomock.NewController(t))
+			logger.EXPECT().Debug(gomock.Any(), gomock.Any()).AnyTimes()
+
+			dm := newBackend(t, logger)
+			defer func() {
+				assert.NoError(t, dm.Close())
+			}()
+
+			test(t, dm)
+		})
+	}
+}
+
+func TestResults(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dm DatabaseManagerInterface) {
+		ctx := context.Background()
+
+		a := models.PageData{URL: "https://example.com/a", MatchingTerms: []string{"fire"}, SimilarityScore: 0.9}
+		b := models.PageData{URL: "https://example.com/b", MatchingTerms: []string{"flood"}, SimilarityScore: 0.5}
+		require.NoError(t, dm.SaveResults(ctx, []models.PageData{a, b}, "site"))
+		require.NoError(t, dm.SaveResults(ctx, []models.PageData{a}, "other"))
+
+		results, err := dm.GetResults(ctx, "site")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []models.PageData{a, b}, results)
+
+		links, err := dm.GetLinks(ctx, "site")
+		require.NoError(t, err)
+		assert.Len(t, links, 2)
+
+		assert.Error(t, dm.SaveResults(ctx, []models.PageData{a}, ""))
+
+		require.NoError(t, dm.ClearResults(ctx, "site"))
+		results, err = dm.GetResults(ctx, "site")
+		require.NoError(t, err)
+		assert.Empty(t, results)
+
+		// Other sites are left alone
+		results, err = dm.GetResults(ctx, "other")
+		require.NoError(t, err)
+		assert.Equal(t, []models.PageData{a}, results)
+
+		// Clearing a site without results is not an error
+		assert.NoError(t, dm.ClearResults(ctx, "missing"))
+	})
+}
+
+func TestSaveResultsUpserts(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dm DatabaseManagerInterface) {
+		ctx := context.Background()
+
+		url := "https://example.com/a"
+		require.NoError(t, dm.SaveResults(ctx, []models.PageData{{URL: url, MatchingTerms: []string{"fire"}, Relevance: 0.4, SimilarityScore: 0.9}}, "site"))
+		require.NoError(t, dm.SaveResults(ctx, []models.PageData{{URL: url, MatchingTerms: []string{"flood", "fire"}, Relevance: 0.7, SimilarityScore: 0.4}}, "site"))
+
+		results, err := dm.GetResults(ctx, "site")
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+
+		got, err := dm.GetResult(ctx, "site", url)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"fire", "flood"}, got.MatchingTerms)
+		assert.Equal(t, 0.9, got.SimilarityScore)
+		assert.Equal(t, 0.7, got.Relevance)
+		assert.Equal(t, results[0], *got)
+
+		_, err = dm.GetResult(ctx, "site", "https://example.com/missing")
+		assert.ErrorIs(t, err, ErrResultNotFound)
+		_, err = dm.GetResult(ctx, "other", url)
+		assert.ErrorIs(t, err, ErrResultNotFound)
+	})
+}
+
+func TestSaveResultsConcurrently(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dm DatabaseManagerInterface) {
+		ctx := context.Background()
+
+		// Workers crawling the same site save the same URL at the same time
+		url := "https://example.com/a"
+		var terms []string
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			term := fmt.Sprintf("term%02d", i)
+			terms = append(terms, term)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, dm.SaveResults(ctx, []models.PageData{{URL: url, MatchingTerms: []string{term}}}, "site"))
+				assert.NoError(t, dm.SaveSeenURLs(ctx, "site", []string{url}, time.Now()))
+			}()
+		}
+		wg.Wait()
+
+		got, err := dm.GetResult(ctx, "site", url)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, terms, got.MatchingTerms)
+
+		seen, err := dm.GetSeenURLs(ctx, "site")
+		require.NoError(t, err)
+		assert.Len(t, seen, 1)
+	})
+}
+
+func TestSaveResultsMetadata(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dm DatabaseManagerInterface) {
+		ctx := context.Background()
+
+		url := "https://example.com/a"
+		published := time.Date(2024, 3, 5, 13, 30, 0, 0, time.UTC)
+		modified := time.Date(2024, 3, 6, 8, 0, 0, 0, time.UTC)
+		metadata := models.PageMetadata{
+			Title:         "Flood warning",
+			Description:   "Avoid the river banks.",
+			Image:         "https://example.com/flood.jpg",
+			CanonicalURL:  "https://example.com/flood-warning",
+			PublishedTime: &published,
+			ModifiedTime:  &modified,
+		}
+
+		// The metadata is saved once the page is fetched, after the match
+		require.NoError(t, dm.SaveResults(ctx, []models.PageData{{URL: url, MatchingTerms: []string{"flood"}, SimilarityScore: 0.5, MatchLocations: []string{models.MatchLocationURL}}}, "site"))
+		require.NoError(t, dm.SaveResults(ctx, []models.PageData{{URL: url, MatchLocations: []string{models.MatchLocationBody}, PageMetadata: metadata}}, "site"))
+
+		got, err := dm.GetResult(ctx, "site", url)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"flood"}, got.MatchingTerms)
+		assert.Equal(t, 0.5, got.SimilarityScore)
+		assert.Equal(t, []string{models.MatchLocationURL, models.MatchLocationBody}, got.MatchLocations)
+		assert.Equal(t, metadata, got.PageMetadata)
+	})
+}
+
+func TestSaveResultsFirstSeen(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dm DatabaseManagerInterface) {
+		ctx := context.Background()
+
+		url := "https://example.com/a"
+		first := time.Date(2024, 3, 5, 13, 30, 0, 500, time.UTC)
+		later := first.Add(24 * time.Hour)
+
+		// A later crawl matching the link again does not make it new
+		require.NoError(t, dm.SaveResults(ctx, []models.PageData{{URL: url, MatchingTerms: []string{"fire"}, FirstSeen: &first, RunID: "run1"}}, "site"))
+		require.NoError(t, dm.SaveResults(ctx, []models.PageData{{URL: url, MatchingTerms: []string{"fire"}, FirstSeen: &later, RunID: "run2"}}, "site"))
+
+		got, err := dm.GetResult(ctx, "site", url)
+		require.NoError(t, err)
+		require.NotNil(t, got.FirstSeen)
+		assert.True(t, first.Equal(*got.FirstSeen))
+		assert.Equal(t, "run1", got.RunID)
+	})
+}
+
+func TestSeenURLs(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dm DatabaseManagerInterface) {
+		ctx := context.Background()
+
+		got, err := dm.GetSeenURLs(ctx, "site")
+		require.NoError(t, err)
+		assert.Empty(t, got)
+
+		first := time.Date(2024, 3, 5, 13, 30, 0, 0, time.UTC)
+		later := first.Add(time.Hour)
+		require.NoError(t, dm.SaveSeenURLs(ctx, "site", []string{"https://example.com/", "https://example.com/a"}, first))
+		require.NoError(t, dm.SaveSeenURLs(ctx, "site", []string{"https://example.com/", "https://example.com/b"}, later))
+		require.NoError(t, dm.SaveSeenURLs(ctx, "other", []string{"https://example.com/c"}, later))
+
+		got, err = dm.GetSeenURLs(ctx, "site")
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+		assert.True(t, first.Equal(got["https://example.com/"]))
+		assert.True(t, first.Equal(got["https://example.com/a"]))
+		assert.True(t, later.Equal(got["https://example.com/b"]))
+
+		assert.Error(t, dm.SaveSeenURLs(ctx, "", []string{"https://example.com/"}, first))
+	})
+}
+
+func TestMigrateResults(t *testing.T) {
+	legacy := []models.PageData{
+		{URL: "https://example.com/a", MatchingTerms: []string{"fire"}, SimilarityScore: 0.9},
+		{URL: "https://example.com/a", MatchingTerms: []string{"flood"}, SimilarityScore: 0.4},
+		{URL: "https://example.com/b", MatchingTerms: []string{"flood"}, SimilarityScore: 0.5},
+	}
+
+	ctx := context.Background()
+	logger := loggo.NewMockLoggerInterface(gomock.NewController(t))
+	logger.EXPECT().Debug(gomock.Any(), gomock.Any()).AnyTimes()
+
+	mr := miniredis.RunT(t)
+	client, err := prowlredis.NewClient(ctx, &prowlredis.Options{Addr: mr.Addr()})
+	require.NoError(t, err)
+	dm := NewRedisManager(client, logger)
+
+	// Save the results the way they used to be saved
+	links, err := marshalResults(legacy)
+	require.NoError(t, err)
+	for _, link := range links {
+		require.NoError(t, dm.client.SAdd(ctx, "site", link))
+	}
+
+	migrated, err := dm.MigrateResults(ctx, "site")
+	require.NoError(t, err)
+	assert.Equal(t, 3, migrated)
+
+	results, err := dm.GetResults(ctx, "site")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "https://example.com/a", results[0].URL)
+	assert.ElementsMatch(t, []string{"fire", "flood"}, results[0].MatchingTerms)
+	assert.Equal(t, 0.9, results[0].SimilarityScore)
+	assert.Equal(t, legacy[2], results[1])
+
+	// The old results are gone, so migrating again is a no-op
+	migrated, err = dm.MigrateResults(ctx, "site")
+	require.NoError(t, err)
+	assert.Equal(t, 0, migrated)
+}
+
+func TestStats(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dm DatabaseManagerInterface) {
+		ctx := context.Background()
+
+		got, err := dm.GetStats(ctx, "site")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+
+		require.NoError(t, dm.SaveStats(ctx, "site", &stats.Stats{TotalPages: 3, TotalLinks: 10, MatchedLinks: 2, NotMatchedLinks: 8}))
+
+		got, err = dm.GetStats(ctx, "site")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, 3, got.GetTotalPages())
+		assert.Equal(t, 10, got.GetTotalLinks())
+		assert.Equal(t, 2, got.GetMatchedLinks())
+		assert.Equal(t, 8, got.GetNotMatchedLinks())
+	})
+}
+
+func TestQueries(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dm DatabaseManagerInterface) {
+		ctx := context.Background()
+
+		got, err := dm.GetQuery(ctx, "site")
+		require.NoError(t, err)
+		assert.Empty(t, got)
+
+		require.NoError(t, dm.SaveQuery(ctx, "site", "(opioid OR fentanyl) AND toronto"))
+		require.NoError(t, dm.SaveQuery(ctx, "other", "fire"))
+
+		got, err = dm.GetQuery(ctx, "site")
+		require.NoError(t, err)
+		assert.Equal(t, "(opioid OR fentanyl) AND toronto", got)
+
+		// Saving an empty query deletes it
+		require.NoError(t, dm.SaveQuery(ctx, "site", ""))
+		got, err = dm.GetQuery(ctx, "site")
+		require.NoError(t, err)
+		assert.Empty(t, got)
+
+		got, err = dm.GetQuery(ctx, "other")
+		require.NoError(t, err)
+		assert.Equal(t, "fire", got)
+	})
+}
+
+func TestCrawlRuns(t *testing.T) {
+	forEachBackend(t, testCrawlRuns)
+}
+
+func testCrawlRuns(t *testing.T, dm DatabaseManagerInterface) {
+	ctx := context.Background()
+
+	started := time.Date(2024, 9, 25, 12, 0, 0, 0, time.UTC)
+	older := &models.CrawlRun{ID: "run1", SiteID: "site", Status: models.CrawlRunStatusRunning, StartedAt: started}
+	newer := &models.CrawlRun{ID: "run2", SiteID: "site", Status: models.CrawlRunStatusRunning, StartedAt: started.Add(time.Hour)}
+	other := &models.CrawlRun{ID: "run3", SiteID: "other", Status: models.CrawlRunStatusRunning, StartedAt: started}
+
+	for _, run := range []*models.CrawlRun{older, newer, other} {
+		require.NoError(t, dm.SaveCrawlRun(ctx, run))
+	}
+
+	// Finishing a run updates the existing record
+	older.Status = models.CrawlRunStatusCompleted
+	ended := started.Add(time.Minute)
+	older.EndedAt = &ended
+	older.TotalPages = 4
+	require.NoError(t, dm.SaveCrawlRun(ctx, older))
+
+	got, err := dm.GetCrawlRun(ctx, "run1")
+	require.NoError(t, err)
+	assert.Equal(t, models.CrawlRunStatusCompleted, got.Status)
+	assert.Equal(t, 4, got.TotalPages)
+	assert.Equal(t, time.Minute, got.Duration())
+
+	// Running runs have no end time
+	got, err = dm.GetCrawlRun(ctx, "run2")
+	require.NoError(t, err)
+	assert.Nil(t, got.EndedAt)
+
+	runs, err := dm.ListCrawlRuns(ctx, "site")
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "run2", runs[0].ID)
+	assert.Equal(t, "run1", runs[1].ID)
+
+	_, err = dm.GetCrawlRun(ctx, "missing")
+	assert.ErrorIs(t, err, ErrCrawlRunNotFound)
+}
+
+func TestCheckpoints(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dm DatabaseManagerInterface) {
+		ctx := context.Background()
+
+		_, err := dm.GetCheckpoint(ctx, "run1")
+		assert.ErrorIs(t, err, ErrCheckpointNotFound)
+
+		checkpoint := &models.CrawlCheckpoint{
+			RunID:      "run1",
+			Queue:      []models.QueuedPage{{URL: "https://example.com/a", Depth: 1}},
+			Visited:    []string{"https://example.com/"},
+			TotalPages: 1,
+			SavedAt:    time.Date(2024, 9, 25, 12, 0, 0, 0, time.UTC),
+		}
+		require.NoError(t, dm.SaveCheckpoint(ctx, checkpoint))
+
+		// Saving again replaces the checkpoint
+		checkpoint.Queue = nil
+		checkpoint.Visited = append(checkpoint.Visited, "https://example.com/a")
+		checkpoint.TotalPages = 2
+		require.NoError(t, dm.SaveCheckpoint(ctx, checkpoint))
+
+		got, err := dm.GetCheckpoint(ctx, "run1")
+		require.NoError(t, err)
+		assert.Empty(t, got.Queue)
+		assert.Equal(t, []string{"https://example.com/", "https://example.com/a"}, got.Visited)
+		assert.Equal(t, 2, got.TotalPages)
+		assert.True(t, checkpoint.SavedAt.Equal(got.SavedAt))
+
+		require.NoError(t, dm.DeleteCheckpoint(ctx, "run1"))
+		_, err = dm.GetCheckpoint(ctx, "run1")
+		assert.ErrorIs(t, err, ErrCheckpointNotFound)
+
+		// Deleting a missing checkpoint is not an error
+		assert.NoError(t, dm.DeleteCheckpoint(ctx, "run1"))
+	})
+}
@@ -0,0 +1,182 @@
+package dbmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jonesrussell/page-prowler/models"
+)
+
+// Fields of the Redis hash of a result
+const (
+	fieldURL             = "url"
+	fieldMatchingTerms   = "matching_terms"
+	fieldRelevance       = "relevance"
+	fieldSimilarityScore = "similarity_score"
+	fieldMatchLocations  = "match_locations"
+	fieldFirstSeen       = "first_seen"
+	fieldRunID           = "run_id"
+	fieldTitle           = "title"
+	fieldDescription     = "description"
+	fieldImage           = "image"
+	fieldCanonicalURL    = "canonical_url"
+	fieldPublishedTime   = "published_time"
+	fieldModifiedTime    = "modified_time"
+	fieldError           = "error"
+)
+
+// pageDataFields returns the field/value pairs of the hash of a result.
+func pageDataFields(pageData models.PageData) ([]interface{}, error) {
+	terms, err := json.Marshal(pageData.MatchingTerms)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling matching terms: %w", err)
+	}
+
+	locations, err := json.Marshal(pageData.MatchLocations)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling match locations: %w", err)
+	}
+
+	var firstSeen, publishedTime, modifiedTime string
+	if pageData.FirstSeen != nil {
+		firstSeen = pageData.FirstSeen.Format(time.RFC3339Nano)
+	}
+	if pageData.PublishedTime != nil {
+		publishedTime = pageData.PublishedTime.Format(time.RFC3339)
+	}
+	if pageData.ModifiedTime != nil {
+		modifiedTime = pageData.ModifiedTime.Format(time.RFC3339)
+	}
+
+	return []interface{}{
+		fieldURL, pageData.URL,
+		fieldMatchingTerms, string(terms),
+		fieldRelevance, strconv.FormatFloat(pageData.Relevance, 'f', -1, 64),
+		fieldSimilarityScore, strconv.FormatFloat(pageData.SimilarityScore, 'f', -1, 64),
+		fieldMatchLocations, string(locations),
+		fieldFirstSeen, firstSeen,
+		fieldRunID, pageData.RunID,
+		fieldTitle, pageData.Title,
+		fieldDescription, pageData.Description,
+		fieldImage, pageData.Image,
+		fieldCanonicalURL, pageData.CanonicalURL,
+		fieldPublishedTime, publishedTime,
+		fieldModifiedTime, modifiedTime,
+		fieldError, pageData.Error,
+	}, nil
+}
+
+// pageDataFromFields decodes the hash of a result.
+func pageDataFromFields(fields map[string]string) (*models.PageData, error) {
+	pageData := &models.PageData{
+		URL:   fields[fieldURL],
+		RunID: fields[fieldRunID],
+		PageMetadata: models.PageMetadata{
+			Title:        fields[fieldTitle],
+			Description:  fields[fieldDescription],
+			Image:        fields[fieldImage],
+			CanonicalURL: fields[fieldCanonicalURL],
+		},
+		Error: fields[fieldError],
+	}
+
+	if terms := fields[fieldMatchingTerms]; terms != "" {
+		if err := json.Unmarshal([]byte(terms), &pageData.MatchingTerms); err != nil {
+			return nil, fmt.Errorf("error unmarshaling matching terms: %w", err)
+		}
+	}
+
+	if locations := fields[fieldMatchLocations]; locations != "" {
+		if err := json.Unmarshal([]byte(locations), &pageData.MatchLocations); err != nil {
+			return nil, fmt.Errorf("error unmarshaling match locations: %w", err)
+		}
+	}
+
+	if relevance := fields[fieldRelevance]; relevance != "" {
+		var err error
+		pageData.Relevance, err = strconv.ParseFloat(relevance, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing relevance: %w", err)
+		}
+	}
+
+	if score := fields[fieldSimilarityScore]; score != "" {
+		var err error
+		pageData.SimilarityScore, err = strconv.ParseFloat(score, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing similarity score: %w", err)
+		}
+	}
+
+	if seen := fields[fieldFirstSeen]; seen != "" {
+		firstSeen, err := time.Parse(time.RFC3339Nano, seen)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing first seen time: %w", err)
+		}
+		pageData.FirstSeen = &firstSeen
+	}
+
+	if published := fields[fieldPublishedTime]; published != "" {
+		publishedTime, err := time.Parse(time.RFC3339, published)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing published time: %w", err)
+		}
+		pageData.PublishedTime = &publishedTime
+	}
+
+	if modified := fields[fieldModifiedTime]; modified != "" {
+		modifiedTime, err := time.Parse(time.RFC3339, modified)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing modified time: %w", err)
+		}
+		pageData.ModifiedTime = &modifiedTime
+	}
+
+	return pageData, nil
+}
+
+// parseSeenURLs decodes seen URLs and the time they were first seen.
+func parseSeenURLs(fields map[string]string) (map[string]time.Time, error) {
+	seen := make(map[string]time.Time, len(fields))
+	for url, value := range fields {
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing first seen time of %s: %w", url, err)
+		}
+		seen[url] = t
+	}
+	return seen, nil
+}
+
+func marshalResults(results []models.PageData) ([]string, error) {
+	links := make([]string, 0, len(results))
+	for _, result := range results {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling PageData: %w", err)
+		}
+		links = append(links, string(data))
+	}
+	return links, nil
+}
+
+func unmarshalResults(links []string) ([]models.PageData, error) {
+	results := make([]models.PageData, 0, len(links))
+	for _, link := range links {
+		var pageData models.PageData
+		if err := json.Unmarshal([]byte(link), &pageData); err != nil {
+			return nil, fmt.Errorf("error unmarshaling PageData: %w", err)
+		}
+		results = append(results, pageData)
+	}
+	return results, nil
+}
+
+func sortResults(results []models.PageData) {
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].URL < results[j].URL
+	})
+}
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/jonesrussell/loggo"
+	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/internal/consumer"
+	"github.com/jonesrussell/page-prowler/internal/tasks"
+)
+
+// DefaultQueue is the Asynq queue crawl tasks are enqueued on.
+const DefaultQueue = "default"
+
+// TaskInspector defines the methods used from asynq.Inspector.
+type TaskInspector interface {
+	GetTaskInfo(queue, id string) (*asynq.TaskInfo, error)
+	DeleteTask(queue, id string) error
+	ListPendingTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+	ListActiveTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+	ListScheduledTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+	ListRetryTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+	ListArchivedTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+	ListCompletedTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+}
+
+var _ TaskInspector = &asynq.Inspector{}
+
+// Server serves the HTTP API described in api.yaml.
+type Server struct {
+	client    tasks.AsynqClient
+	inspector TaskInspector
+	manager   crawler.CrawlManagerInterface
+	logger    loggo.LoggerInterface
+}
+
+// MatchlinksRequest is the request body of POST /matchlinks.
+type MatchlinksRequest struct {
+	URL                   string
+	StartURLs             []string
+	AllowedDomains        []string
+	SearchTerms           string
+	CrawlSiteID           string
+	MaxDepth              int
+	Debug                 bool
+	MaxConcurrentRequests int
+	DelayBetweenRequests  string
+	RandomDelay           string
+	DiscoverFeeds         bool
+	DomainLimits          []string
+	Exclude               []string
+	Feeds                 []string
+	Include               []string
+	Incremental           bool
+	Language              string
+	MaxDuration           string
+	MaxPages              int
+	MatchContent          bool
+	Matchers              []string
+	Query                 string
+	SimilarityMetric      string
+	SimilarityThreshold   float64
+}
+
+// Task is the API representation of an Asynq crawl task.
+type Task struct {
+	Type    string
+	Payload string
+	ID      string
+	Queue   string
+	State   string
+	Retries int
+	LastErr string
+	Timeout string
+}
+
+// MessageResponse is the body of responses that only carry a message.
+type MessageResponse struct {
+	ID      string `json:"id,omitempty"`
+	Message string `json:"message"`
+}
+
+// ErrorResponse is the body of every error response.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer creates a new API server.
+func NewServer(
+	client tasks.AsynqClient,
+	inspector TaskInspector,
+	manager crawler.CrawlManagerInterface,
+) *Server {
+	return &Server{
+		client:    client,
+		inspector: inspector,
+		manager:   manager,
+		logger:    manager.GetLogger(),
+	}
+}
+
+// BasePath is the path the API routes are served under, the server URL of
+// api.yaml.
+const BasePath = "/v1"
+
+// Handler returns the http.Handler with all API routes registered under
+// BasePath.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+BasePath+"/ping", s.handlePing)
+	mux.HandleFunc("GET "+BasePath+"/matchlinks", s.handleListMatchlinks)
+	mux.HandleFunc("POST "+BasePath+"/matchlinks", s.handleCreateMatchlinks)
+	mux.HandleFunc("GET "+BasePath+"/matchlinks/{id}", s.handleGetMatchlinks)
+	mux.HandleFunc("DELETE "+BasePath+"/matchlinks/{id}", s.handleDeleteMatchlinks)
+	mux.HandleFunc("GET "+BasePath+"/getlinks", s.handleGetLinks)
+	return mux
+}
+
+func (s *Server) handlePing(w http.ResponseWriter, _ *http.Request) {
+	s.writeJSON(w, http.StatusOK, MessageResponse{Message: "pong"})
+}
+
+func (s *Server) handleListMatchlinks(w http.ResponseWriter, _ *http.Request) {
+	listers := []func(string, ...asynq.ListOption) ([]*asynq.TaskInfo, error){
+		s.inspector.ListActiveTasks,
+		s.inspector.ListPendingTasks,
+		s.inspector.ListScheduledTasks,
+		s.inspector.ListRetryTasks,
+		s.inspector.ListArchivedTasks,
+		s.inspector.ListCompletedTasks,
+	}
+
+	taskList := make([]Task, 0)
+	for _, list := range listers {
+		infos, err := list(DefaultQueue)
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			// Nothing has been enqueued yet
+			break
+		}
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to list tasks: %v", err))
+			return
+		}
+		for _, info := range infos {
+			if info.Type == tasks.CrawlTaskType {
+				taskList = append(taskList, newTask(info))
+			}
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, taskList)
+}
+
+func (s *Server) handleCreateMatchlinks(w http.ResponseWriter, r *http.Request) {
+	var req MatchlinksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+		return
+	}
+
+	payload := &tasks.CrawlTaskPayload{
+		URL:                   req.URL,
+		StartURLs:             req.StartURLs,
+		AllowedDomains:        req.AllowedDomains,
+		SearchTerms:           req.SearchTerms,
+		CrawlSiteID:           req.CrawlSiteID,
+		MaxDepth:              req.MaxDepth,
+		Debug:                 req.Debug,
+		MaxConcurrentRequests: req.MaxConcurrentRequests,
+		DelayBetweenRequests:  req.DelayBetweenRequests,
+		RandomDelay:           req.RandomDelay,
+		DiscoverFeeds:         req.DiscoverFeeds,
+		DomainLimits:          req.DomainLimits,
+		Exclude:               req.Exclude,
+		Feeds:                 req.Feeds,
+		Include:               req.Include,
+		Incremental:           req.Incremental,
+		Language:              req.Language,
+		MaxDuration:           req.MaxDuration,
+		MaxPages:              req.MaxPages,
+		MatchContent:          req.MatchContent,
+		Matchers:              req.Matchers,
+		Query:                 req.Query,
+		SimilarityMetric:      req.SimilarityMetric,
+		SimilarityThreshold:   req.SimilarityThreshold,
+	}
+
+	// Validate up front so a bad payload is a client error rather than a server error
+	if _, err := tasks.NewCrawlTask(payload); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := tasks.EnqueueCrawlTask(s.client, payload)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to enqueue task: %v", err))
+		return
+	}
+
+	s.logger.Info("Enqueued crawl task", "id", id, "siteid", req.CrawlSiteID)
+	s.writeJSON(w, http.StatusCreated, MessageResponse{ID: id, Message: "Matching task created"})
+}
+
+func (s *Server) handleGetMatchlinks(w http.ResponseWriter, r *http.Request) {
+	info, err := s.inspector.GetTaskInfo(DefaultQueue, r.PathValue("id"))
+	if err != nil {
+		s.writeInspectorError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, newTask(info))
+}
+
+func (s *Server) handleDeleteMatchlinks(w http.ResponseWriter, r *http.Request) {
+	if err := s.inspector.DeleteTask(DefaultQueue, r.PathValue("id")); err != nil {
+		s.writeInspectorError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, MessageResponse{Message: "Matching task deleted"})
+}
+
+func (s *Server) handleGetLinks(w http.ResponseWriter, r *http.Request) {
+	siteid := r.URL.Query().Get("siteid")
+	if siteid == "" {
+		s.writeError(w, http.StatusBadRequest, errors.New("siteid is required"))
+		return
+	}
+
+	sortBy := r.URL.Query().Get("sort")
+	if sortBy == "" {
+		sortBy = consumer.SortURL
+	}
+
+	var minScore float64
+	if value := r.URL.Query().Get("minscore"); value != "" {
+		var err error
+		minScore, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid minscore: %v", err))
+			return
+		}
+	}
+
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		since, err = consumer.ParseSince(value, time.Now())
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	links, err := consumer.RetrieveAndUnmarshalLinks(r.Context(), s.manager, siteid)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	links = consumer.FilterLinks(links, minScore)
+	if !since.IsZero() {
+		links = consumer.FilterSince(links, since)
+	}
+	if newOnly, _ := strconv.ParseBool(r.URL.Query().Get("newonly")); newOnly {
+		runID, err := consumer.LatestRunID(r.Context(), s.manager, siteid)
+		if errors.Is(err, consumer.ErrNoCrawlRuns) {
+			s.writeError(w, http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		links = consumer.FilterRun(links, runID)
+	}
+	if err := consumer.SortLinks(links, sortBy); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, consumer.CreateOutput(siteid, links))
+}
+
+func (s *Server) writeInspectorError(w http.ResponseWriter, err error) {
+	if errors.Is(err, asynq.ErrQueueNotFound) || errors.Is(err, asynq.ErrTaskNotFound) {
+		s.writeError(w, http.StatusNotFound, errors.New("task not found"))
+		return
+	}
+	s.writeError(w, http.StatusInternalServerError, err)
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, err error) {
+	if status >= http.StatusInternalServerError {
+		s.logger.Error("API request failed", err)
+	}
+	s.writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Error("Failed to write response", err)
+	}
+}
+
+func newTask(info *asynq.TaskInfo) Task {
+	return Task{
+		Type:    info.Type,
+		Payload: string(info.Payload),
+		ID:      info.ID,
+		Queue:   info.Queue,
+		State:   info.State.String(),
+		Retries: info.Retried,
+		LastErr: info.LastErr,
+		Timeout: info.Timeout.String(),
+	}
+}
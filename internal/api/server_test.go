@@ -0,0 +1,235 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/hibiken/asynq"
+	"github.com/jonesrussell/loggo"
+	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/jonesrussell/page-prowler/internal/consumer"
+	"github.com/jonesrussell/page-prowler/internal/prowlredis"
+	"github.com/jonesrussell/page-prowler/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) (*Server, *crawler.CrawlManager) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	ctrl := gomock.NewController(t)
+	logger := loggo.NewMockLoggerInterface(ctrl)
+	logger.EXPECT().Debug(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Error(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	redisClient, err := prowlredis.NewClient(context.Background(), &prowlredis.Options{Addr: mr.Addr()})
+	require.NoError(t, err)
+
+	dbManager := dbmanager.NewRedisManager(redisClient, logger)
+	manager := crawler.NewCrawlManager(logger, dbManager, nil, &crawler.CrawlOptions{})
+
+	redisConnOpt := asynq.RedisClientOpt{Addr: mr.Addr()}
+	client := asynq.NewClient(redisConnOpt)
+	inspector := asynq.NewInspector(redisConnOpt)
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = inspector.Close()
+	})
+
+	return NewServer(client, inspector, manager), manager
+}
+
+func doRequest(t *testing.T, s *Server, method, target string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPing(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	rec := doRequest(t, s, http.MethodGet, "/v1/ping", nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"message":"pong"}`, rec.Body.String())
+
+	// The routes are only served under the base path of api.yaml
+	rec = doRequest(t, s, http.MethodGet, "/ping", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestMatchlinksLifecycle(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	// No tasks have been enqueued yet
+	rec := doRequest(t, s, http.MethodGet, "/v1/matchlinks", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[]`, rec.Body.String())
+
+	body, err := json.Marshal(MatchlinksRequest{
+		URL:         "https://www.example.com",
+		SearchTerms: "keyword1,keyword2",
+		CrawlSiteID: "siteID",
+		MaxDepth:    1,
+	})
+	require.NoError(t, err)
+
+	rec = doRequest(t, s, http.MethodPost, "/v1/matchlinks", body)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created MessageResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	require.NotEmpty(t, created.ID)
+
+	rec = doRequest(t, s, http.MethodGet, "/v1/matchlinks", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var list []Task
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, created.ID, list[0].ID)
+
+	rec = doRequest(t, s, http.MethodGet, "/v1/matchlinks/"+created.ID, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var task Task
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &task))
+	assert.Equal(t, "crawl", task.Type)
+	assert.Equal(t, DefaultQueue, task.Queue)
+	assert.Equal(t, "pending", task.State)
+	assert.Contains(t, task.Payload, `"crawl_site_id":"siteID"`)
+
+	rec = doRequest(t, s, http.MethodDelete, "/v1/matchlinks/"+created.ID, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(t, s, http.MethodGet, "/v1/matchlinks/"+created.ID, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCreateMatchlinksInvalid(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "malformed json", body: `{"URL":`},
+		{name: "missing siteid", body: `{"URL":"https://www.example.com","SearchTerms":"a"}`},
+		{name: "invalid query", body: `{"URL":"https://www.example.com","Query":"fire AND","CrawlSiteID":"site"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, s, http.MethodPost, "/v1/matchlinks", []byte(tt.body))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
+
+func TestGetLinks(t *testing.T) {
+	s, manager := newTestServer(t)
+
+	rec := doRequest(t, s, http.MethodGet, "/v1/getlinks", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	pageData := models.PageData{
+		URL:           "https://www.example.com/the-cat-has-been-abducted",
+		MatchingTerms: []string{"abduct"},
+	}
+	err := manager.GetDBManager().SaveResults(context.Background(), []models.PageData{pageData}, "siteID")
+	require.NoError(t, err)
+
+	rec = doRequest(t, s, http.MethodGet, "/v1/getlinks?siteid=siteID", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var output consumer.Output
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &output))
+	assert.Equal(t, "siteID", output.Siteid)
+	assert.Equal(t, []consumer.Link{{URL: pageData.URL, MatchingTerms: pageData.MatchingTerms}}, output.Links)
+}
+
+func TestGetLinksSortedByScore(t *testing.T) {
+	s, manager := newTestServer(t)
+
+	pages := []models.PageData{
+		{URL: "https://www.example.com/a", MatchingTerms: []string{"fire"}, Relevance: 0.3},
+		{URL: "https://www.example.com/b", MatchingTerms: []string{"fire"}, Relevance: 0.9},
+		{URL: "https://www.example.com/c", MatchingTerms: []string{"fire"}, Relevance: 0.6},
+	}
+	require.NoError(t, manager.GetDBManager().SaveResults(context.Background(), pages, "siteID"))
+
+	rec := doRequest(t, s, http.MethodGet, "/v1/getlinks?siteid=siteID&sort=score&minscore=0.5", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var output consumer.Output
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &output))
+	require.Len(t, output.Links, 2)
+	assert.Equal(t, "https://www.example.com/b", output.Links[0].URL)
+	assert.Equal(t, "https://www.example.com/c", output.Links[1].URL)
+
+	rec = doRequest(t, s, http.MethodGet, "/v1/getlinks?siteid=siteID&sort=date", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doRequest(t, s, http.MethodGet, "/v1/getlinks?siteid=siteID&minscore=high", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGetLinksNewOnly(t *testing.T) {
+	s, manager := newTestServer(t)
+	dbManager := manager.GetDBManager()
+
+	rec := doRequest(t, s, http.MethodGet, "/v1/getlinks?siteid=siteID&newonly=true", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	first := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	second := time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC)
+	third := time.Date(2024, 3, 3, 8, 0, 0, 0, time.UTC)
+	require.NoError(t, dbManager.SaveCrawlRun(context.Background(), &models.CrawlRun{ID: "run1", SiteID: "siteID", Status: models.CrawlRunStatusCompleted, StartedAt: first}))
+	require.NoError(t, dbManager.SaveCrawlRun(context.Background(), &models.CrawlRun{ID: "run2", SiteID: "siteID", Status: models.CrawlRunStatusCompleted, StartedAt: second}))
+
+	// The links of a crawl that is still running are not new yet
+	require.NoError(t, dbManager.SaveCrawlRun(context.Background(), &models.CrawlRun{ID: "run3", SiteID: "siteID", Status: models.CrawlRunStatusRunning, StartedAt: third}))
+
+	pages := []models.PageData{
+		{URL: "https://www.example.com/a", MatchingTerms: []string{"fire"}, FirstSeen: &first, RunID: "run1"},
+		{URL: "https://www.example.com/b", MatchingTerms: []string{"fire"}, FirstSeen: &second, RunID: "run2"},
+		{URL: "https://www.example.com/c", MatchingTerms: []string{"fire"}, FirstSeen: &third, RunID: "run3"},
+	}
+	require.NoError(t, dbManager.SaveResults(context.Background(), pages, "siteID"))
+
+	rec = doRequest(t, s, http.MethodGet, "/v1/getlinks?siteid=siteID&newonly=true", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var output consumer.Output
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &output))
+	require.Len(t, output.Links, 1)
+	assert.Equal(t, "https://www.example.com/b", output.Links[0].URL)
+	assert.Equal(t, "run2", output.Links[0].RunID)
+	assert.True(t, second.Equal(*output.Links[0].FirstSeen))
+
+	rec = doRequest(t, s, http.MethodGet, "/v1/getlinks?siteid=siteID&since=2024-03-02T00:00:00Z", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	output = consumer.Output{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &output))
+	require.Len(t, output.Links, 2)
+	assert.Equal(t, "run2", output.Links[0].RunID)
+	assert.Equal(t, "run3", output.Links[1].RunID)
+
+	rec = doRequest(t, s, http.MethodGet, "/v1/getlinks?siteid=siteID&since=yesterday", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -3,10 +3,13 @@ package consumer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/models"
 )
 
 type Output struct {
@@ -18,16 +21,146 @@ type Output struct {
 }
 
 type Link struct {
-	URL           string   `json:"url"`
-	MatchingTerms []string `json:"matching_terms"`
+	URL           string     `json:"url"`
+	MatchingTerms []string   `json:"matching_terms"`
+	Relevance     float64    `json:"relevance,omitempty"`
+	FirstSeen     *time.Time `json:"first_seen,omitempty"`
+	RunID         string     `json:"run_id,omitempty"`
+}
+
+// Orders links can be sorted in
+const (
+	SortScore = "score"
+	SortURL   = "url"
+)
+
+// SortLinks sorts links by relevance, most relevant first, or by URL.
+func SortLinks(links []Link, sortBy string) error {
+	return sortByKey(links, func(i int) sortKey {
+		return sortKey{relevance: links[i].Relevance, url: links[i].URL}
+	}, sortBy)
+}
+
+// SortPages sorts pages like SortLinks. Results saved before relevance was
+// scored fall back to their similarity score.
+func SortPages(pages []models.PageData, sortBy string) error {
+	return sortByKey(pages, func(i int) sortKey {
+		return sortKey{relevance: pages[i].Relevance, similarity: pages[i].SimilarityScore, url: pages[i].URL}
+	}, sortBy)
+}
+
+// sortKey is what links and pages are sorted by.
+type sortKey struct {
+	relevance  float64
+	similarity float64
+	url        string
+}
+
+// sortByKey sorts slice, whose element i has key(i), in the order sortBy.
+func sortByKey(slice interface{}, key func(i int) sortKey, sortBy string) error {
+	var less func(a, b sortKey) bool
+	switch sortBy {
+	case SortScore:
+		less = func(a, b sortKey) bool {
+			if a.relevance != b.relevance {
+				return a.relevance > b.relevance
+			}
+			if a.similarity != b.similarity {
+				return a.similarity > b.similarity
+			}
+			return a.url < b.url
+		}
+	case SortURL:
+		less = func(a, b sortKey) bool {
+			return a.url < b.url
+		}
+	default:
+		return fmt.Errorf("unknown sort order: %s", sortBy)
+	}
+
+	sort.SliceStable(slice, func(i, j int) bool {
+		return less(key(i), key(j))
+	})
+	return nil
+}
+
+// FilterLinks returns the links with a relevance of at least minScore.
+func FilterLinks(links []Link, minScore float64) []Link {
+	if minScore <= 0 {
+		return links
+	}
+
+	filtered := make([]Link, 0, len(links))
+	for _, link := range links {
+		if link.Relevance >= minScore {
+			filtered = append(filtered, link)
+		}
+	}
+	return filtered
+}
+
+// ErrNoCrawlRuns is returned when the links of the latest crawl of a site are
+// asked for and no crawl of the site has completed.
+var ErrNoCrawlRuns = errors.New("no completed crawl runs")
+
+// ParseSince parses the time links are filtered from: an RFC 3339 time, a
+// date such as "2024-03-05", or a duration before now such as "24h".
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: expected an RFC 3339 time, a date such as 2024-03-05 or a duration such as 24h", value)
+}
+
+// FilterSince returns the links first seen at or after since.
+func FilterSince(links []Link, since time.Time) []Link {
+	filtered := make([]Link, 0, len(links))
+	for _, link := range links {
+		if link.FirstSeen != nil && !link.FirstSeen.Before(since) {
+			filtered = append(filtered, link)
+		}
+	}
+	return filtered
+}
+
+// FilterRun returns the links first found by the crawl run with the ID runID.
+func FilterRun(links []Link, runID string) []Link {
+	filtered := make([]Link, 0, len(links))
+	for _, link := range links {
+		if link.RunID == runID {
+			filtered = append(filtered, link)
+		}
+	}
+	return filtered
+}
+
+// LatestRunID returns the ID of the latest completed crawl run of a site. A
+// crawl that is running, or did not finish, has not found all its links.
+func LatestRunID(ctx context.Context, manager crawler.CrawlManagerInterface, siteid string) (string, error) {
+	runs, err := manager.GetDBManager().ListCrawlRuns(ctx, siteid)
+	if err != nil {
+		return "", fmt.Errorf("failed to list crawl runs: %v", err)
+	}
+	for _, run := range runs {
+		if run.Status == models.CrawlRunStatusCompleted {
+			return run.ID, nil
+		}
+	}
+	return "", fmt.Errorf("%w for site %s", ErrNoCrawlRuns, siteid)
 }
 
 func RetrieveAndUnmarshalLinks(ctx context.Context, manager crawler.CrawlManagerInterface, siteid string) ([]Link, error) {
 	dbManager := manager.GetDBManager()
 
-	links, err := dbManager.GetLinksFromRedis(ctx, siteid)
+	links, err := dbManager.GetLinks(ctx, siteid)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get links from Redis: %v", err)
+		return nil, fmt.Errorf("failed to get links: %v", err)
 	}
 
 	var linkStructs []Link
@@ -1,20 +1,46 @@
 package drug
 
 import (
+	_ "embed"
 	"strings"
 
 	"github.com/adrg/strutil/metrics"
 	"github.com/jonesrussell/page-prowler/internal/matcher"
+	"github.com/jonesrussell/page-prowler/internal/topic"
 )
 
-const SimilarityThreshold = 1
+// Name is the name the matcher is registered under.
+const Name = "drug"
 
+//go:embed drug.yaml
+var dictionaryData []byte
+
+// Dictionary holds the terms of the topic, read from drug.yaml.
+var Dictionary = topic.MustParse(dictionaryData, ".yaml")
+
+func init() {
+	topic.Register(matcher.DefaultRegistry, Dictionary)
+}
+
+// Matcher matches URLs about drugs.
 type Matcher struct {
-	*matcher.BaseMatcher
+	topic *topic.Matcher
 }
 
+// NewMatcher creates a Matcher that compares terms with swg, or the default
+// Smith-Waterman-Gotoh if it is nil, and the threshold of the dictionary.
 func NewMatcher(swg *metrics.SmithWatermanGotoh) *Matcher {
-	return &Matcher{BaseMatcher: matcher.NewBaseMatcher(swg)}
+	var similarity matcher.Similarity
+	if swg != nil {
+		similarity = matcher.Similarity{Name: matcher.MetricSmithWatermanGotoh, Metric: swg}
+	}
+	return NewMatcherWithSimilarity(similarity)
+}
+
+// NewMatcherWithSimilarity creates a Matcher that compares terms with the
+// metric and threshold of similarity.
+func NewMatcherWithSimilarity(similarity matcher.Similarity) *Matcher {
+	return &Matcher{topic: topic.NewMatcher(Dictionary, similarity)}
 }
 
 func (m *Matcher) Match(href string) bool {
@@ -30,27 +56,5 @@ func (m *Matcher) Match(href string) bool {
 		title = sliced[len(sliced)-2]
 	}
 
-	// Use BaseMatcher's ProcessContent method
-	title = m.ProcessContent(title)
-
-	if title == "" {
-		return false
-	}
-
-	// Define drug-related terms
-	drugTerms := []string{
-		"drug", "smoke joint", "prescription", "medication", "pharmacy", "medicine",
-		"treatment", "health", "wellness", "pharmaceutical", "dosage", "side effects",
-		"prescription drug", "over the counter", "drug interaction", "drug-abuse",
-		"drug addiction", "drug rehabilitation", "drug policy", "drug regulation",
-	}
-
-	// Check for matches
-	for _, term := range drugTerms {
-		if m.Similarity(term, title) >= SimilarityThreshold {
-			return true
-		}
-	}
-
-	return false
+	return m.topic.MatchText(title)
 }
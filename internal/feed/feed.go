@@ -0,0 +1,268 @@
+// Package feed parses the lists of pages sites publish for crawlers and
+// readers: sitemaps, sitemap indexes, news sitemaps, and RSS and Atom feeds.
+package feed
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrUnknownFormat is returned for documents that are not a sitemap or feed.
+var ErrUnknownFormat = errors.New("not a sitemap or feed")
+
+// ErrTooLarge is returned for gzipped documents larger than MaxSize once
+// decompressed.
+var ErrTooLarge = errors.New("sitemap or feed too large")
+
+// MaxSize is the size of the largest sitemap the sitemap protocol allows.
+const MaxSize = 50 << 20
+
+// Entry is a page listed by a sitemap or feed.
+type Entry struct {
+	URL       string
+	Title     string     // the title of news sitemaps and feeds
+	Language  string     // the language of news sitemaps and feeds, if given
+	Published *time.Time // the publication date of news sitemaps and feeds
+	Modified  *time.Time // the lastmod of sitemaps, or updated of Atom feeds
+}
+
+// Document is a parsed sitemap or feed. A sitemap index lists further
+// Sitemaps instead of entries.
+type Document struct {
+	Entries  []Entry
+	Sitemaps []string
+}
+
+type urlset struct {
+	URLs []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+		News    struct {
+			Title           string `xml:"title"`
+			PublicationDate string `xml:"publication_date"`
+			Publication     struct {
+				Language string `xml:"language"`
+			} `xml:"publication"`
+		} `xml:"news"`
+	} `xml:"url"`
+}
+
+type sitemapindex struct {
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+type rssItem struct {
+	Title   string `xml:"title"`
+	Link    string `xml:"link"`
+	GUID    string `xml:"guid"`
+	PubDate string `xml:"pubDate"`
+	Date    string `xml:"date"` // dc:date
+}
+
+type rss struct {
+	Language string    `xml:"channel>language"`
+	Items    []rssItem `xml:"channel>item"`
+}
+
+// rdf is RSS 1.0, whose items are next to the channel.
+type rdf struct {
+	Language string    `xml:"channel>language"`
+	Items    []rssItem `xml:"item"`
+}
+
+type atom struct {
+	Language string `xml:"lang,attr"`
+	Entries  []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// Parse parses a sitemap, sitemap index, news sitemap, RSS or Atom feed,
+// gzipped or not. The format is told by the root element.
+func Parse(data []byte) (*Document, error) {
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress: %v", err)
+		}
+		if data, err = io.ReadAll(io.LimitReader(r, MaxSize+1)); err != nil {
+			return nil, fmt.Errorf("failed to decompress: %v", err)
+		}
+		if len(data) > MaxSize {
+			return nil, fmt.Errorf("%w: more than %d bytes decompressed", ErrTooLarge, MaxSize)
+		}
+	}
+
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	switch root {
+	case "urlset":
+		var v urlset
+		if err := xml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		for _, u := range v.URLs {
+			doc.add(Entry{
+				URL:       u.Loc,
+				Title:     u.News.Title,
+				Language:  u.News.Publication.Language,
+				Published: parseTime(u.News.PublicationDate),
+				Modified:  parseTime(u.LastMod),
+			})
+		}
+	case "sitemapindex":
+		var v sitemapindex
+		if err := xml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		for _, sitemap := range v.Sitemaps {
+			if loc := strings.TrimSpace(sitemap.Loc); loc != "" {
+				doc.Sitemaps = append(doc.Sitemaps, loc)
+			}
+		}
+	case "rss":
+		var v rss
+		if err := xml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		doc.addItems(v.Items, v.Language)
+	case "RDF":
+		var v rdf
+		if err := xml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		doc.addItems(v.Items, v.Language)
+	case "feed":
+		var v atom
+		if err := xml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		for _, e := range v.Entries {
+			var link string
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			doc.add(Entry{
+				URL:       link,
+				Title:     e.Title,
+				Language:  v.Language,
+				Published: parseTime(e.Published),
+				Modified:  parseTime(e.Updated),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("%w: root element %q", ErrUnknownFormat, root)
+	}
+
+	return doc, nil
+}
+
+// RobotsSitemaps returns the URLs of the sitemaps listed in a robots.txt.
+func RobotsSitemaps(robots []byte) []string {
+	var sitemaps []string
+	scanner := bufio.NewScanner(bytes.NewReader(robots))
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "sitemap") {
+			if value = strings.TrimSpace(value); value != "" {
+				sitemaps = append(sitemaps, value)
+			}
+		}
+	}
+	return sitemaps
+}
+
+func (d *Document) addItems(items []rssItem, language string) {
+	for _, item := range items {
+		link := strings.TrimSpace(item.Link)
+		if link == "" && strings.HasPrefix(strings.TrimSpace(item.GUID), "http") {
+			link = item.GUID
+		}
+		published := parseTime(item.PubDate)
+		if published == nil {
+			published = parseTime(item.Date)
+		}
+		d.add(Entry{URL: link, Title: item.Title, Language: language, Published: published})
+	}
+}
+
+// add adds entry, trimmed, if it has a URL.
+func (d *Document) add(entry Entry) {
+	entry.URL = strings.TrimSpace(entry.URL)
+	if entry.URL == "" {
+		return
+	}
+	entry.Title = strings.Join(strings.Fields(entry.Title), " ")
+	entry.Language = strings.TrimSpace(entry.Language)
+	d.Entries = append(d.Entries, entry)
+}
+
+// rootElement returns the local name of the root element of an XML document.
+func rootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return "", ErrUnknownFormat
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// timeLayouts are the date formats of sitemaps (W3C Datetime) and feeds
+// (RFC 822 for RSS, RFC 3339 for Atom).
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+}
+
+// parseTime parses a date in any of timeLayouts, or returns nil.
+func parseTime(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
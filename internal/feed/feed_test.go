@@ -0,0 +1,173 @@
+package feed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const newsSitemap = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"
+        xmlns:news="http://www.google.com/schemas/sitemap-news/0.9">
+  <url>
+    <loc>https://example.com/news/fentanyl-seized</loc>
+    <lastmod>2024-03-05T14:00:00+00:00</lastmod>
+    <news:news>
+      <news:publication>
+        <news:name>Example News</news:name>
+        <news:language>en</news:language>
+      </news:publication>
+      <news:publication_date>2024-03-05T13:30:00Z</news:publication_date>
+      <news:title>Police seize
+        fentanyl</news:title>
+    </news:news>
+  </url>
+  <url>
+    <loc> https://example.com/about </loc>
+    <lastmod>2023-01-01</lastmod>
+  </url>
+  <url><loc></loc></url>
+</urlset>`
+
+const sitemapIndex = `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/sitemap-news.xml</loc></sitemap>
+  <sitemap><loc>https://example.com/sitemap-2024.xml.gz</loc><lastmod>2024-03-01</lastmod></sitemap>
+</sitemapindex>`
+
+const rssFeed = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>Example</title>
+    <link>https://example.com/</link>
+    <language>fr-CA</language>
+    <item>
+      <title>Saisie record d'opioïdes</title>
+      <link>https://example.com/actualites/saisie-record</link>
+      <pubDate>Tue, 05 Mar 2024 13:30:00 -0500</pubDate>
+    </item>
+    <item>
+      <title>Sans lien</title>
+      <guid>https://example.com/actualites/sans-lien</guid>
+      <dc:date>2024-03-04</dc:date>
+    </item>
+  </channel>
+</rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="es">
+  <title>Example</title>
+  <entry>
+    <title>La policía desmantela una red</title>
+    <link rel="self" href="https://example.com/feed/1"/>
+    <link rel="alternate" href="https://example.com/sociedad/red"/>
+    <published>2024-03-05T13:30:00Z</published>
+    <updated>2024-03-06T08:00:00Z</updated>
+  </entry>
+</feed>`
+
+func date(value string) *time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want *Document
+	}{
+		{
+			name: "news sitemap",
+			data: newsSitemap,
+			want: &Document{Entries: []Entry{
+				{
+					URL:       "https://example.com/news/fentanyl-seized",
+					Title:     "Police seize fentanyl",
+					Language:  "en",
+					Published: date("2024-03-05T13:30:00Z"),
+					Modified:  date("2024-03-05T14:00:00Z"),
+				},
+				{URL: "https://example.com/about", Modified: date("2023-01-01T00:00:00Z")},
+			}},
+		},
+		{
+			name: "sitemap index",
+			data: sitemapIndex,
+			want: &Document{Sitemaps: []string{"https://example.com/sitemap-news.xml", "https://example.com/sitemap-2024.xml.gz"}},
+		},
+		{
+			name: "RSS",
+			data: rssFeed,
+			want: &Document{Entries: []Entry{
+				{URL: "https://example.com/actualites/saisie-record", Title: "Saisie record d'opioïdes", Language: "fr-CA", Published: date("2024-03-05T18:30:00Z")},
+				{URL: "https://example.com/actualites/sans-lien", Title: "Sans lien", Language: "fr-CA", Published: date("2024-03-04T00:00:00Z")},
+			}},
+		},
+		{
+			name: "Atom",
+			data: atomFeed,
+			want: &Document{Entries: []Entry{
+				{
+					URL:       "https://example.com/sociedad/red",
+					Title:     "La policía desmantela una red",
+					Language:  "es",
+					Published: date("2024-03-05T13:30:00Z"),
+					Modified:  date("2024-03-06T08:00:00Z"),
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseGzip(t *testing.T) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(sitemapIndex))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	got, err := Parse(buf.Bytes())
+	require.NoError(t, err)
+	assert.Len(t, got.Sitemaps, 2)
+}
+
+func TestParseGzipTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(`<?xml version="1.0"?><urlset>`))
+	require.NoError(t, err)
+	_, err = w.Write(make([]byte, MaxSize))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	_, err = Parse(buf.Bytes())
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	for _, data := range []string{"", "<html><body>Not found</body></html>", "User-agent: *"} {
+		_, err := Parse([]byte(data))
+		assert.ErrorIs(t, err, ErrUnknownFormat, data)
+	}
+}
+
+func TestRobotsSitemaps(t *testing.T) {
+	robots := "User-agent: *\nDisallow: /admin\nSitemap: https://example.com/sitemap.xml\nsitemap:https://example.com/news.xml\nSitemap:\n"
+	assert.Equal(t, []string{"https://example.com/sitemap.xml", "https://example.com/news.xml"}, RobotsSitemaps([]byte(robots)))
+}
@@ -0,0 +1,190 @@
+package language
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stemFrench is the light stemmer for French of Jacques Savoy. It removes
+// plurals, the feminine and common derivational suffixes, accents and
+// doubled letters, so that "opioïdes" and "opioide" share the stem "opioid".
+func stemFrench(word string) string {
+	s := []rune(word)
+	n := len(s)
+
+	if n > 5 && s[n-1] == 'x' {
+		if s[n-3] == 'a' && s[n-2] == 'u' && s[n-4] != 'e' {
+			s[n-2] = 'l'
+		}
+		n--
+	}
+	if n > 3 && s[n-1] == 'x' {
+		n--
+	}
+	if n > 3 && s[n-1] == 's' {
+		n--
+	}
+
+	s = s[:n]
+	switch {
+	case n > 9 && hasSuffix(s, "issement"):
+		s = s[:n-6]
+		s[len(s)-1] = 'r'
+		return normalizeFrench(s)
+	case n > 8 && hasSuffix(s, "issant"):
+		s = s[:n-4]
+		s[len(s)-1] = 'r'
+		return normalizeFrench(s)
+	case n > 6 && hasSuffix(s, "ement"):
+		s = s[:n-4]
+		if len(s) > 3 && hasSuffix(s, "ive") {
+			s = s[:len(s)-1]
+			s[len(s)-1] = 'f'
+		}
+		return normalizeFrench(s)
+	case n > 11 && hasSuffix(s, "ficatrice"):
+		s = s[:n-5]
+		s[len(s)-2], s[len(s)-1] = 'e', 'r'
+		return normalizeFrench(s)
+	case n > 10 && hasSuffix(s, "ficateur"):
+		s = s[:n-4]
+		s[len(s)-2], s[len(s)-1] = 'e', 'r'
+		return normalizeFrench(s)
+	case n > 9 && hasSuffix(s, "catrice"):
+		s = s[:n-3]
+		s[len(s)-4], s[len(s)-3], s[len(s)-2] = 'q', 'u', 'e'
+		return normalizeFrench(s)
+	case n > 8 && hasSuffix(s, "cateur"):
+		s = s[:n-2]
+		s[len(s)-4], s[len(s)-3], s[len(s)-2], s[len(s)-1] = 'q', 'u', 'e', 'r'
+		return normalizeFrench(s)
+	case n > 8 && hasSuffix(s, "atrice"):
+		s = s[:n-4]
+		s[len(s)-2], s[len(s)-1] = 'e', 'r'
+		return normalizeFrench(s)
+	case n > 7 && hasSuffix(s, "ateur"):
+		s = s[:n-3]
+		s[len(s)-2], s[len(s)-1] = 'e', 'r'
+		return normalizeFrench(s)
+	}
+
+	if n > 6 && hasSuffix(s, "trice") {
+		s = s[:n-1]
+		n = len(s)
+		s[n-3], s[n-2], s[n-1] = 'e', 'u', 'r'
+	}
+
+	switch {
+	case n > 5 && hasSuffix(s, "ième"):
+		return normalizeFrench(s[:n-4])
+	case n > 7 && hasSuffix(s, "teuse"):
+		s = s[:n-2]
+		s[len(s)-1] = 'r'
+		return normalizeFrench(s)
+	case n > 6 && hasSuffix(s, "teur"):
+		s = s[:n-1]
+		s[len(s)-1] = 'r'
+		return normalizeFrench(s)
+	case n > 5 && hasSuffix(s, "euse"):
+		return normalizeFrench(s[:n-2])
+	case n > 8 && hasSuffix(s, "ère"):
+		s = s[:n-1]
+		s[len(s)-2] = 'e'
+		return normalizeFrench(s)
+	case n > 7 && hasSuffix(s, "ive"):
+		s = s[:n-1]
+		s[len(s)-1] = 'f'
+		return normalizeFrench(s)
+	case n > 4 && (hasSuffix(s, "folle") || hasSuffix(s, "molle")):
+		s = s[:n-2]
+		s[len(s)-1] = 'u'
+		return normalizeFrench(s)
+	case n > 9 && hasSuffix(s, "nnelle"):
+		return normalizeFrench(s[:n-5])
+	case n > 9 && hasSuffix(s, "nnel"):
+		return normalizeFrench(s[:n-3])
+	}
+
+	if n > 4 && hasSuffix(s, "ète") {
+		s = s[:n-1]
+		n = len(s)
+		s[n-2] = 'e'
+	}
+	if n > 8 && hasSuffix(s, "ique") {
+		s = s[:n-4]
+		n = len(s)
+	}
+
+	switch {
+	case n > 8 && hasSuffix(s, "esse"):
+		return normalizeFrench(s[:n-3])
+	case n > 7 && hasSuffix(s, "inage"):
+		return normalizeFrench(s[:n-3])
+	case n > 9 && hasSuffix(s, "isation"):
+		s = s[:n-7]
+		if len(s) > 5 && hasSuffix(s, "ual") {
+			s[len(s)-2] = 'e'
+		}
+		return normalizeFrench(s)
+	case n > 9 && hasSuffix(s, "isateur"):
+		return normalizeFrench(s[:n-7])
+	case n > 8 && hasSuffix(s, "ation"):
+		return normalizeFrench(s[:n-5])
+	case n > 8 && hasSuffix(s, "ition"):
+		return normalizeFrench(s[:n-5])
+	}
+
+	return normalizeFrench(s)
+}
+
+// frenchAccents maps the accented letters of French to the letters without
+// the accent.
+var frenchAccents = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e',
+	'î': 'i', 'ï': 'i',
+	'ô': 'o',
+	'ù': 'u', 'û': 'u',
+	'ç': 'c',
+}
+
+// normalizeFrench removes the accents and doubled letters of words longer
+// than four letters, and their final "ie", "r" and "e".
+func normalizeFrench(s []rune) string {
+	if len(s) > 4 {
+		var normalized []rune
+		for i, r := range s {
+			if plain, ok := frenchAccents[r]; ok {
+				r = plain
+			}
+			if i > 0 && unicode.IsLetter(r) && r == normalized[len(normalized)-1] {
+				continue
+			}
+			normalized = append(normalized, r)
+		}
+		s = normalized
+	}
+
+	if len(s) > 4 && hasSuffix(s, "ie") {
+		s = s[:len(s)-2]
+	}
+	if len(s) > 4 {
+		if s[len(s)-1] == 'r' {
+			s = s[:len(s)-1]
+		}
+		if s[len(s)-1] == 'e' {
+			s = s[:len(s)-1]
+		}
+		if s[len(s)-1] == 'e' {
+			s = s[:len(s)-1]
+		}
+		if s[len(s)-1] == s[len(s)-2] && unicode.IsLetter(s[len(s)-1]) {
+			s = s[:len(s)-1]
+		}
+	}
+	return string(s)
+}
+
+func hasSuffix(s []rune, suffix string) bool {
+	return strings.HasSuffix(string(s), suffix)
+}
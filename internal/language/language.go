@@ -0,0 +1,114 @@
+// Package language processes text in the languages matching supports:
+// removing stopwords, stemming and detecting which of them a text is in.
+package language
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bbalet/stopwords"
+	"github.com/caneroj1/stemmer"
+)
+
+// The ISO 639-1 codes of the supported languages.
+const (
+	English = "en"
+	French  = "fr"
+	Spanish = "es"
+)
+
+// Default is the language of text in an unknown or unsupported language.
+const Default = English
+
+// Supported lists the supported languages, the default first.
+var Supported = []string{English, French, Spanish}
+
+// ErrUnsupported is returned for languages without a stemmer.
+var ErrUnsupported = errors.New("unsupported language")
+
+// stemmers stem a lower case word of each supported language.
+var stemmers = map[string]func(string) string{
+	English: func(word string) string { return strings.ToLower(stemmer.Stem(word)) },
+	French:  stemFrench,
+	Spanish: stemSpanish,
+}
+
+// elisions matches the articles and pronouns French elides before a vowel,
+// as in "l'opioïde" or "qu'il", and as they are left in slugs such as
+// "saisie-d-opioides".
+var elisions = regexp.MustCompile(`(?i)\b(?:c|d|j|l|m|n|s|t|qu|jusqu|lorsqu|puisqu|quoiqu)(?:['’]|\s|$)`)
+
+// Normalize returns the code of the language of a BCP 47 tag, such as "fr"
+// for "fr-CA", or "" if the language is not supported.
+func Normalize(tag string) string {
+	code := strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	if _, ok := stemmers[code]; !ok {
+		return ""
+	}
+	return code
+}
+
+// Parse returns the code of the language of tag, or an error if it is not
+// supported.
+func Parse(tag string) (string, error) {
+	code := Normalize(tag)
+	if code == "" {
+		return "", fmt.Errorf("%w %q, expected one of %s", ErrUnsupported, tag, strings.Join(Supported, ", "))
+	}
+	return code, nil
+}
+
+// RemoveStopwords replaces hyphens with spaces and removes the stopwords of
+// lang from content, lower casing it. French elisions are removed too.
+// Unsupported languages are processed as the default.
+func RemoveStopwords(content string, lang string) string {
+	lang = orDefault(lang)
+
+	content = strings.ReplaceAll(content, "-", " ") // Remove hyphens
+	if lang == French {
+		content = elisions.ReplaceAllString(content, " ")
+	}
+	return strings.TrimSpace(stopwords.CleanString(content, lang, true))
+}
+
+// Stem returns the lower case stems of words in lang.
+func Stem(words []string, lang string) []string {
+	stem := stemmers[orDefault(lang)]
+
+	stems := make([]string, len(words))
+	for i, word := range words {
+		stems[i] = stem(strings.ToLower(word))
+	}
+	return stems
+}
+
+// Process removes the stopwords of lang from content and stems it, as text
+// is processed before it is matched.
+func Process(content string, lang string) string {
+	return strings.Join(Stem(strings.Fields(RemoveStopwords(content, lang)), lang), " ")
+}
+
+// Detect returns the supported language text is most likely in: the one
+// with the most stopwords in it, or the default if none has more than it.
+func Detect(text string) string {
+	best := Default
+	fewest := len(strings.Fields(stopwords.CleanString(text, Default, true)))
+	for _, lang := range Supported {
+		if words := len(strings.Fields(stopwords.CleanString(text, lang, true))); words < fewest {
+			best, fewest = lang, words
+		}
+	}
+	return best
+}
+
+func orDefault(lang string) string {
+	if code := Normalize(lang); code != "" {
+		return code
+	}
+	return Default
+}
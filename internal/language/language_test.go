@@ -0,0 +1,86 @@
+package language
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"en":    English,
+		"fr-CA": French,
+		"FR":    French,
+		"es_MX": Spanish,
+		" es ":  Spanish,
+		"de":    "",
+		"":      "",
+	}
+
+	for tag, want := range tests {
+		assert.Equal(t, want, Normalize(tag), tag)
+	}
+
+	_, err := Parse("de")
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		lang  string
+		words []string
+		want  string
+	}{
+		{English, []string{"seized", "seizes", "seizing"}, "seiz"},
+		{French, []string{"opioïdes", "opioide", "opioïde"}, "opioid"},
+		{French, []string{"policiers", "policière", "policier"}, "polici"},
+		{French, []string{"saisie", "saisies"}, "sais"},
+		{French, []string{"trafiquants", "trafiquant"}, "trafiquant"},
+		{French, []string{"inondations", "inondation"}, "inond"},
+		{Spanish, []string{"policía", "policías", "policia"}, "polici"},
+		{Spanish, []string{"incendios", "incendio"}, "incendi"},
+		{Spanish, []string{"tráfico", "trafico"}, "trafic"},
+		{Spanish, []string{"luces", "luz"}, "luz"},
+	}
+
+	for _, tt := range tests {
+		for _, stem := range Stem(tt.words, tt.lang) {
+			assert.Equal(t, tt.want, stem, "%s %v", tt.lang, tt.words)
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		lang    string
+		want    string
+	}{
+		{"English", "police-seize-drugs-at-the-border", English, "polic seiz drug border"},
+		{"French slug", "saisie-record-d-opioides-a-montreal", French, "sais record opioid montreal"},
+		{"French elision", "L'opioïde qu'ils ont trouvé", French, "opioid trouv"},
+		{"Spanish slug", "la-policia-desmantela-una-red-de-trafico-de-fentanilo", Spanish, "polici desmantel red trafic fentanil"},
+		{"Unsupported language", "police-seize-drugs", "de", "polic seiz drug"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Process(tt.content, tt.lang))
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := map[string]string{
+		"The police seized a record amount of drugs at the border on Tuesday":   English,
+		"La police a saisi une quantité record de drogues à la frontière mardi": French,
+		"La policía desmantela una red de tráfico de fentanilo en la frontera":  Spanish,
+		"Fentanyl Toronto Montreal": English,
+		"":                          English,
+	}
+
+	for text, want := range tests {
+		assert.Equal(t, want, Detect(text), text)
+	}
+}
@@ -0,0 +1,40 @@
+package language
+
+import "strings"
+
+// spanishAccents are replaced by the letters without the accent.
+var spanishAccents = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ä", "a",
+	"è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i",
+	"ò", "o", "ó", "o", "ô", "o", "ö", "o",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u",
+)
+
+// stemSpanish is the light stemmer for Spanish of Jacques Savoy. It removes
+// accents and the endings of gender and number, so that "policía",
+// "policías" and "policia" share the stem "polici".
+func stemSpanish(word string) string {
+	s := []rune(spanishAccents.Replace(word))
+	n := len(s)
+	if n < 5 {
+		return string(s)
+	}
+
+	switch s[n-1] {
+	case 'o', 'a', 'e':
+		return string(s[:n-1])
+	case 's':
+		if s[n-2] == 'e' && s[n-3] == 's' && s[n-4] == 'e' {
+			return string(s[:n-2])
+		}
+		if s[n-2] == 'e' && s[n-3] == 'c' {
+			s[n-3] = 'z'
+			return string(s[:n-2])
+		}
+		if s[n-2] == 'o' || s[n-2] == 'a' || s[n-2] == 'e' {
+			return string(s[:n-2])
+		}
+	}
+	return string(s)
+}
@@ -5,33 +5,39 @@ import (
 
 	"github.com/adrg/strutil"
 	"github.com/adrg/strutil/metrics"
-	"github.com/bbalet/stopwords"
 	"github.com/caneroj1/stemmer"
+	"github.com/jonesrussell/page-prowler/internal/language"
 )
 
 type BaseMatcher struct {
-	swg *metrics.SmithWatermanGotoh
+	metric strutil.StringMetric
 }
 
 // NewBaseMatcher creates a new BaseMatcher with a provided SmithWatermanGotoh instance.
 func NewBaseMatcher(swg *metrics.SmithWatermanGotoh) *BaseMatcher {
 	if swg == nil {
-		swg = metrics.NewSmithWatermanGotoh() // Default instance if none provided
-		swg.CaseSensitive = false
-		swg.GapPenalty = -0.1
-		swg.Substitution = metrics.MatchMismatch{
-			Match:    1,
-			Mismatch: -0.5,
-		}
+		swg = newSmithWatermanGotoh() // Default instance if none provided
 	}
-	return &BaseMatcher{swg: swg}
+	return &BaseMatcher{metric: swg}
+}
+
+// NewBaseMatcherWithMetric creates a new BaseMatcher that compares terms with metric.
+func NewBaseMatcherWithMetric(metric strutil.StringMetric) *BaseMatcher {
+	if metric == nil {
+		return NewBaseMatcher(nil)
+	}
+	return &BaseMatcher{metric: metric}
 }
 
 // ProcessContent processes the content by removing hyphens, stopwords, and stemming.
 func (bm *BaseMatcher) ProcessContent(content string) string {
-	content = strings.ReplaceAll(content, "-", " ")                         // Remove hyphens
-	content = strings.TrimSpace(stopwords.CleanString(content, "en", true)) // Remove stopwords
-	return bm.StemAndLowerContent(content)                                  // Combine stemming and lowercasing
+	return bm.ProcessContentIn(content, language.English)
+}
+
+// ProcessContentIn processes the content like ProcessContent, removing the
+// stopwords of and stemming in lang, an ISO 639-1 code such as "en" or "fr".
+func (bm *BaseMatcher) ProcessContentIn(content string, lang string) string {
+	return language.Process(content, lang)
 }
 
 // StemAndLowerContent stems the content and returns the processed string.
@@ -47,5 +53,5 @@ func (bm *BaseMatcher) StemAndLowerContent(content string) string {
 
 // Similarity checks the similarity between two terms.
 func (bm *BaseMatcher) Similarity(term1, term2 string) float64 {
-	return strutil.Similarity(term1, term2, bm.swg)
+	return strutil.Similarity(term1, term2, bm.metric)
 }
@@ -0,0 +1,135 @@
+package matcher
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownMatcher is returned for matcher names that are not registered.
+var ErrUnknownMatcher = errors.New("unknown matcher")
+
+// Factory creates a Matcher. The similarity is the one of the crawl, or the
+// zero Similarity if the crawl does not set one, in which case the Matcher
+// uses its own. A zero threshold is one the crawl does not set.
+type Factory func(similarity Similarity) Matcher
+
+// Registry holds the matchers crawls can enable by name.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// DefaultRegistry is the registry the topic matchers register with.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register adds the matcher created by factory under name, replacing any
+// matcher already registered under that name.
+func Register(name string, factory Factory) {
+	DefaultRegistry.Register(name, factory)
+}
+
+// Register adds the matcher created by factory under name, replacing any
+// matcher already registered under that name.
+func (r *Registry) Register(name string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[strings.ToLower(name)] = factory
+}
+
+// Unregister removes the matcher registered under name, if any.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.factories, strings.ToLower(name))
+}
+
+// Names returns the names of the registered matchers, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.namesLocked()
+}
+
+// Validate checks that every name is registered.
+func (r *Registry) Validate(names []string) error {
+	_, err := r.New(names, Similarity{})
+	return err
+}
+
+// New creates the matchers registered under names, comparing terms with
+// similarity.
+func (r *Registry) New(names []string, similarity Similarity) ([]Matcher, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matchers []Matcher
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		factory, ok := r.factories[name]
+		if !ok {
+			return nil, fmt.Errorf("%w %q, expected one of %s", ErrUnknownMatcher, name, strings.Join(r.namesLocked(), ", "))
+		}
+		matchers = append(matchers, factory(similarity))
+	}
+	return matchers, nil
+}
+
+func (r *Registry) namesLocked() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NamedMatcher is a Matcher for a topic. When it matches, the topic is
+// reported as the matching term rather than the search terms.
+type NamedMatcher interface {
+	Matcher
+	Name() string
+}
+
+// Scorer is a Matcher that also scores how much content is about its topic,
+// such as by the weights of the terms of the topic it has.
+type Scorer interface {
+	Score(content string) float64
+}
+
+// Topic adapts a function that reports whether a URL, or text, is about a
+// topic to the Matcher interface.
+type Topic struct {
+	name  string
+	match func(content string) bool
+}
+
+var _ NamedMatcher = &Topic{}
+
+// NewTopic creates a Topic named name that matches content with match.
+func NewTopic(name string, match func(content string) bool) *Topic {
+	return &Topic{name: name, match: match}
+}
+
+// Name returns the name of the topic.
+func (t *Topic) Name() string {
+	return t.name
+}
+
+// Match reports whether content is about the topic. The pattern is ignored.
+func (t *Topic) Match(content string, _ string) (bool, error) {
+	if content == "" {
+		return false, nil
+	}
+	return t.match(content), nil
+}
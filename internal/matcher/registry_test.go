@@ -0,0 +1,43 @@
+package matcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("Fire", func(similarity Similarity) Matcher {
+		return NewTopic("fire", func(content string) bool {
+			return strings.Contains(content, "fire")
+		})
+	})
+	registry.Register("flood", func(similarity Similarity) Matcher {
+		return NewTopic("flood", func(content string) bool {
+			return strings.Contains(content, "flood")
+		})
+	})
+
+	assert.Equal(t, []string{"fire", "flood"}, registry.Names())
+
+	matchers, err := registry.New([]string{"FIRE", " flood", ""}, Similarity{})
+	require.NoError(t, err)
+	require.Len(t, matchers, 2)
+
+	matched, err := matchers[0].Match("warehouse fire", "")
+	require.NoError(t, err)
+	assert.True(t, matched)
+	assert.Equal(t, "fire", matchers[0].(NamedMatcher).Name())
+
+	matched, err = matchers[1].Match("warehouse fire", "")
+	require.NoError(t, err)
+	assert.False(t, matched)
+
+	_, err = registry.New([]string{"fire", "sports"}, Similarity{})
+	assert.ErrorIs(t, err, ErrUnknownMatcher)
+	assert.ErrorIs(t, registry.Validate([]string{"sports"}), ErrUnknownMatcher)
+	assert.NoError(t, registry.Validate(nil))
+}
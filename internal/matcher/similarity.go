@@ -0,0 +1,113 @@
+package matcher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adrg/strutil"
+	"github.com/adrg/strutil/metrics"
+)
+
+// The string metrics terms can be compared with.
+const (
+	MetricSmithWatermanGotoh = "swg"
+	MetricJaroWinkler        = "jaro-winkler"
+	MetricLevenshtein        = "levenshtein"
+	MetricJaccard            = "jaccard"
+)
+
+// Metrics lists the names accepted by NewMetric.
+var Metrics = []string{MetricSmithWatermanGotoh, MetricJaroWinkler, MetricLevenshtein, MetricJaccard}
+
+// defaultThresholds are the similarities above which terms match when no
+// threshold is given. The metrics score differently, Levenshtein and Jaccard
+// penalise a different ending more than the others.
+var defaultThresholds = map[string]float64{
+	MetricSmithWatermanGotoh: 0.9,
+	MetricJaroWinkler:        0.9,
+	MetricLevenshtein:        0.8,
+	MetricJaccard:            0.5,
+}
+
+// Similarity is a string metric and the similarity above which two strings
+// match.
+type Similarity struct {
+	Name      string
+	Metric    strutil.StringMetric
+	Threshold float64
+}
+
+// NewSimilarity returns the metric named name, Smith-Waterman-Gotoh if it is
+// empty, with threshold, or the default threshold of the metric if it is 0.
+func NewSimilarity(name string, threshold float64) (Similarity, error) {
+	if threshold < 0 || threshold > 1 {
+		return Similarity{}, fmt.Errorf("similarity threshold must be between 0 and 1, got %v", threshold)
+	}
+
+	name = strings.ToLower(name)
+	metric, err := NewMetric(name)
+	if err != nil {
+		return Similarity{}, err
+	}
+	if name == "" {
+		name = MetricSmithWatermanGotoh
+	}
+	if threshold == 0 {
+		threshold = defaultThresholds[name]
+	}
+
+	return Similarity{Name: name, Metric: metric, Threshold: threshold}, nil
+}
+
+// NewMetric returns the case insensitive metric named name. An empty name is
+// Smith-Waterman-Gotoh, set up as it always has been.
+func NewMetric(name string) (strutil.StringMetric, error) {
+	switch strings.ToLower(name) {
+	case "", MetricSmithWatermanGotoh:
+		return newSmithWatermanGotoh(), nil
+	case MetricJaroWinkler:
+		jw := metrics.NewJaroWinkler()
+		jw.CaseSensitive = false
+		return jw, nil
+	case MetricLevenshtein:
+		levenshtein := metrics.NewLevenshtein()
+		levenshtein.CaseSensitive = false
+		return levenshtein, nil
+	case MetricJaccard:
+		// Jaccard index of the character bigrams, or shingles, of the strings
+		jaccard := metrics.NewJaccard()
+		jaccard.CaseSensitive = false
+		return jaccard, nil
+	default:
+		return nil, fmt.Errorf("unknown similarity metric %q, expected one of %s", name, strings.Join(Metrics, ", "))
+	}
+}
+
+func newSmithWatermanGotoh() *metrics.SmithWatermanGotoh {
+	swg := metrics.NewSmithWatermanGotoh()
+	swg.CaseSensitive = false
+	swg.GapPenalty = -0.1
+	swg.Substitution = metrics.MatchMismatch{
+		Match:    1,
+		Mismatch: -0.5,
+	}
+	return swg
+}
+
+// IsLocal reports whether the metric scores how well the shorter string
+// aligns with part of the longer one, as Smith-Waterman-Gotoh does, rather
+// than how alike the whole strings are.
+func (s Similarity) IsLocal() bool {
+	_, ok := s.Metric.(*metrics.SmithWatermanGotoh)
+	return ok
+}
+
+// Compare returns the similarity of a and b, between 0 and 1.
+func (s Similarity) Compare(a, b string) float64 {
+	return strutil.Similarity(a, b, s.Metric)
+}
+
+// Match reports whether a and b are at least as similar as the threshold.
+func (s Similarity) Match(a, b string) bool {
+	return s.Compare(a, b) >= s.Threshold
+}
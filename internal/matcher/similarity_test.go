@@ -0,0 +1,88 @@
+package matcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSimilarity(t *testing.T) {
+	tests := []struct {
+		name          string
+		metric        string
+		threshold     float64
+		wantName      string
+		wantThreshold float64
+		wantErr       bool
+	}{
+		{name: "Default metric", metric: "", wantName: MetricSmithWatermanGotoh, wantThreshold: 0.9},
+		{name: "Default threshold", metric: MetricJaccard, wantName: MetricJaccard, wantThreshold: 0.5},
+		{name: "Given threshold", metric: MetricLevenshtein, threshold: 0.7, wantName: MetricLevenshtein, wantThreshold: 0.7},
+		{name: "Name is case insensitive", metric: "Jaro-Winkler", wantName: MetricJaroWinkler, wantThreshold: 0.9},
+		{name: "Unknown metric", metric: "soundex", wantErr: true},
+		{name: "Negative threshold", metric: MetricJaccard, threshold: -0.1, wantErr: true},
+		{name: "Threshold above 1", metric: MetricJaccard, threshold: 1.5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			similarity, err := NewSimilarity(tt.metric, tt.threshold)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, similarity.Name)
+			assert.Equal(t, tt.wantThreshold, similarity.Threshold)
+		})
+	}
+}
+
+// TestSimilarityCorpus checks which pairs of terms each metric matches at
+// its default threshold.
+func TestSimilarityCorpus(t *testing.T) {
+	corpus := []struct {
+		a, b string
+		want map[string]bool
+	}{
+		{
+			a: "fentanyl", b: "fentanyl",
+			want: map[string]bool{MetricSmithWatermanGotoh: true, MetricJaroWinkler: true, MetricLevenshtein: true, MetricJaccard: true},
+		},
+		{
+			a: "Fentanyl", b: "fentanyl",
+			want: map[string]bool{MetricSmithWatermanGotoh: true, MetricJaroWinkler: true, MetricLevenshtein: true, MetricJaccard: true},
+		},
+		{
+			a: "fentanyl", b: "fentanil",
+			want: map[string]bool{MetricSmithWatermanGotoh: false, MetricJaroWinkler: true, MetricLevenshtein: true, MetricJaccard: true},
+		},
+		{
+			a: "fire", b: "firefight",
+			want: map[string]bool{MetricSmithWatermanGotoh: true, MetricJaroWinkler: false, MetricLevenshtein: false, MetricJaccard: false},
+		},
+		{
+			a: "flood", b: "budget",
+			want: map[string]bool{MetricSmithWatermanGotoh: false, MetricJaroWinkler: false, MetricLevenshtein: false, MetricJaccard: false},
+		},
+		{
+			a: "drug policy", b: "drug polici",
+			want: map[string]bool{MetricSmithWatermanGotoh: true, MetricJaroWinkler: true, MetricLevenshtein: true, MetricJaccard: true},
+		},
+	}
+
+	for _, name := range Metrics {
+		similarity, err := NewSimilarity(name, 0)
+		require.NoError(t, err)
+
+		for _, tt := range corpus {
+			t.Run(name+"/"+tt.a+"/"+tt.b, func(t *testing.T) {
+				score := similarity.Compare(tt.a, tt.b)
+				assert.GreaterOrEqual(t, score, 0.0)
+				assert.LessOrEqual(t, score, 1.0)
+				assert.Equal(t, tt.want[name], similarity.Match(tt.a, tt.b), "score %v", score)
+			})
+		}
+	}
+}
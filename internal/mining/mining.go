@@ -1,21 +1,46 @@
 package mining
 
 import (
-	"fmt"
+	_ "embed"
 	"strings"
 
 	"github.com/adrg/strutil/metrics"
 	"github.com/jonesrussell/page-prowler/internal/matcher"
+	"github.com/jonesrussell/page-prowler/internal/topic"
 )
 
-const SimilarityThreshold = 0.6
+// Name is the name the matcher is registered under.
+const Name = "mining"
 
+//go:embed mining.yaml
+var dictionaryData []byte
+
+// Dictionary holds the terms of the topic, read from mining.yaml.
+var Dictionary = topic.MustParse(dictionaryData, ".yaml")
+
+func init() {
+	topic.Register(matcher.DefaultRegistry, Dictionary)
+}
+
+// Matcher matches URLs about mining.
 type Matcher struct {
-	*matcher.BaseMatcher
+	topic *topic.Matcher
 }
 
+// NewMatcher creates a Matcher that compares terms with swg, or the default
+// Smith-Waterman-Gotoh if it is nil, and the threshold of the dictionary.
 func NewMatcher(swg *metrics.SmithWatermanGotoh) *Matcher {
-	return &Matcher{BaseMatcher: matcher.NewBaseMatcher(swg)}
+	var similarity matcher.Similarity
+	if swg != nil {
+		similarity = matcher.Similarity{Name: matcher.MetricSmithWatermanGotoh, Metric: swg}
+	}
+	return NewMatcherWithSimilarity(similarity)
+}
+
+// NewMatcherWithSimilarity creates a Matcher that compares terms with the
+// metric and threshold of similarity.
+func NewMatcherWithSimilarity(similarity matcher.Similarity) *Matcher {
+	return &Matcher{topic: topic.NewMatcher(Dictionary, similarity)}
 }
 
 func (m *Matcher) Match(href string) bool {
@@ -31,45 +56,5 @@ func (m *Matcher) Match(href string) bool {
 		title = sliced[len(sliced)-2]
 	}
 
-	// Use BaseMatcher's ProcessContent method
-	title = m.ProcessContent(title)
-
-	if title == "" {
-		return false
-	}
-
-	// Define mining-related terms
-	miningTerms := []string{
-		"mining", "gold", "silver", "copper", "coal", "ore", "excavation", "drilling",
-		"exploration", "mineral", "quarry", "sustainability", "environmental impact",
-		"resource", "extraction", "geology", "mineral rights", "mine safety",
-		"junior mining stocks", "gold mining", "silver mining", "copper mining",
-		"lead mining", "zinc mining", "exploration projects", "mining companies",
-		"market data", "stock quotes", "real-time news", "mining sectors",
-		"mining regions", "high-grade deposits", "mining districts", "mining news",
-	}
-
-	// Define terms that should automatically score 0
-	excludedTerms := []string{
-		"technology", "sports", "entertainment", "fashion", "music", "movies",
-	}
-
-	// Check for excluded terms
-	for _, term := range excludedTerms {
-		if strings.Contains(title, term) {
-			fmt.Printf("Excluding term '%s' found in title '%s'\n", term, title)
-			return false
-		}
-	}
-
-	// Check for matches
-	for _, term := range miningTerms {
-		score := m.Similarity(term, title)
-		fmt.Printf("Matching '%s' with '%s': score = %f\n", term, title, score)
-		if score >= SimilarityThreshold {
-			return true
-		}
-	}
-
-	return false
+	return m.topic.MatchText(title)
 }
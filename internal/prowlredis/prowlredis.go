@@ -2,6 +2,7 @@ package prowlredis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -19,11 +20,21 @@ type Options struct {
 type ClientInterface interface {
 	Ping(ctx context.Context) error
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Get(ctx context.Context, key string) (string, error)
 	SAdd(ctx context.Context, key string, members ...interface{}) error
 	Del(ctx context.Context, keys ...string) error
 	SMembers(ctx context.Context, key string) ([]string, error)
 	SIsMember(ctx context.Context, key string, member interface{}) (bool, error)
+	HSet(ctx context.Context, key string, values ...interface{}) error
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	// HSetNX sets the fields of values that the hash at key does not have.
+	HSetNX(ctx context.Context, key string, values map[string]interface{}) error
+	// HUpdate sets the fields update returns for the current fields of the
+	// hash at key, atomically: it calls update again if the hash changes
+	// before the fields are set.
+	HUpdate(ctx context.Context, key string, update func(fields map[string]string) ([]interface{}, error)) error
 	Options() *Options
+	Close() error
 }
 
 // Client represents the Redis client.
@@ -36,10 +47,29 @@ type ClientRedis struct {
 	*redis.Client
 }
 
+// ErrNil is returned by Get when the key does not exist.
+var ErrNil = redis.Nil
+
+// maxUpdateAttempts is how many times HUpdate tries to update a hash that
+// other clients keep changing, waiting a little longer after each attempt.
+const (
+	maxUpdateAttempts = 20
+	updateBackoff     = time.Millisecond
+)
+
 // Set implements ClientInterface.
 // Subtle: this method shadows the method (*Client).Set of ClientRedis.Client.
-func (c *ClientRedis) Set(_ context.Context, _ string, _ interface{}, _ time.Duration) error {
-	panic("unimplemented")
+func (c *ClientRedis) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if key == "" {
+		return fmt.Errorf("key is not set")
+	}
+	return c.Client.Set(ctx, key, value, expiration).Err()
+}
+
+// Get implements ClientInterface.
+// It returns ErrNil if the key does not exist.
+func (c *ClientRedis) Get(ctx context.Context, key string) (string, error) {
+	return c.Client.Get(ctx, key).Result()
 }
 
 func (c *ClientRedis) Close() error {
@@ -71,6 +101,72 @@ func (c *ClientRedis) SIsMember(ctx context.Context, key string, member interfac
 	return c.Client.SIsMember(ctx, key, member).Result()
 }
 
+// HSet implements ClientInterface.
+func (c *ClientRedis) HSet(ctx context.Context, key string, values ...interface{}) error {
+	if key == "" {
+		return fmt.Errorf("key is not set")
+	}
+	return c.Client.HSet(ctx, key, values...).Err()
+}
+
+// HGetAll implements ClientInterface.
+// It returns an empty map if the key does not exist.
+func (c *ClientRedis) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return c.Client.HGetAll(ctx, key).Result()
+}
+
+// HSetNX implements ClientInterface.
+func (c *ClientRedis) HSetNX(ctx context.Context, key string, values map[string]interface{}) error {
+	if key == "" {
+		return fmt.Errorf("key is not set")
+	}
+	_, err := c.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for field, value := range values {
+			pipe.HSetNX(ctx, key, field, value)
+		}
+		return nil
+	})
+	return err
+}
+
+// HUpdate implements ClientInterface. It watches the hash, so that the fields
+// are only set if no other client changed it since it was read.
+func (c *ClientRedis) HUpdate(ctx context.Context, key string, update func(fields map[string]string) ([]interface{}, error)) error {
+	if key == "" {
+		return fmt.Errorf("key is not set")
+	}
+
+	txf := func(tx *redis.Tx) error {
+		fields, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		values, err := update(fields)
+		if err != nil || len(values) == 0 {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, values...)
+			return nil
+		})
+		return err
+	}
+
+	for i := 0; i < maxUpdateAttempts; i++ {
+		err := c.Client.Watch(ctx, txf, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(i+1) * updateBackoff):
+		}
+	}
+	return fmt.Errorf("failed to update %s: %w", key, redis.TxFailedErr)
+}
+
 func (c *ClientRedis) Options() *Options {
 	opts := c.Client.Options()
 	return &Options{
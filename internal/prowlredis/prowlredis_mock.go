@@ -7,7 +7,7 @@ package prowlredis
 import (
 	context "context"
 	reflect "reflect"
-	"time"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -18,11 +18,6 @@ type MockClientInterface struct {
 	recorder *MockClientInterfaceMockRecorder
 }
 
-// Set implements ClientInterface.
-func (m *MockClientInterface) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	panic("unimplemented")
-}
-
 // MockClientInterfaceMockRecorder is the mock recorder for MockClientInterface.
 type MockClientInterfaceMockRecorder struct {
 	mock *MockClientInterface
@@ -40,6 +35,20 @@ func (m *MockClientInterface) EXPECT() *MockClientInterfaceMockRecorder {
 	return m.recorder
 }
 
+// Close mocks base method.
+func (m *MockClientInterface) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockClientInterfaceMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockClientInterface)(nil).Close))
+}
+
 // Del mocks base method.
 func (m *MockClientInterface) Del(ctx context.Context, keys ...string) error {
 	m.ctrl.T.Helper()
@@ -59,6 +68,83 @@ func (mr *MockClientInterfaceMockRecorder) Del(ctx interface{}, keys ...interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Del", reflect.TypeOf((*MockClientInterface)(nil).Del), varargs...)
 }
 
+// Get mocks base method.
+func (m *MockClientInterface) Get(ctx context.Context, key string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockClientInterfaceMockRecorder) Get(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockClientInterface)(nil).Get), ctx, key)
+}
+
+// HGetAll mocks base method.
+func (m *MockClientInterface) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HGetAll", ctx, key)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HGetAll indicates an expected call of HGetAll.
+func (mr *MockClientInterfaceMockRecorder) HGetAll(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HGetAll", reflect.TypeOf((*MockClientInterface)(nil).HGetAll), ctx, key)
+}
+
+// HSet mocks base method.
+func (m *MockClientInterface) HSet(ctx context.Context, key string, values ...interface{}) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, key}
+	for _, a := range values {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "HSet", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HSet indicates an expected call of HSet.
+func (mr *MockClientInterfaceMockRecorder) HSet(ctx, key interface{}, values ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, key}, values...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HSet", reflect.TypeOf((*MockClientInterface)(nil).HSet), varargs...)
+}
+
+// HSetNX mocks base method.
+func (m *MockClientInterface) HSetNX(ctx context.Context, key string, values map[string]interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HSetNX", ctx, key, values)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HSetNX indicates an expected call of HSetNX.
+func (mr *MockClientInterfaceMockRecorder) HSetNX(ctx, key, values interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HSetNX", reflect.TypeOf((*MockClientInterface)(nil).HSetNX), ctx, key, values)
+}
+
+// HUpdate mocks base method.
+func (m *MockClientInterface) HUpdate(ctx context.Context, key string, update func(map[string]string) ([]interface{}, error)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HUpdate", ctx, key, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HUpdate indicates an expected call of HUpdate.
+func (mr *MockClientInterfaceMockRecorder) HUpdate(ctx, key, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HUpdate", reflect.TypeOf((*MockClientInterface)(nil).HUpdate), ctx, key, update)
+}
+
 // Options mocks base method.
 func (m *MockClientInterface) Options() *Options {
 	m.ctrl.T.Helper()
@@ -121,6 +207,20 @@ func (mr *MockClientInterfaceMockRecorder) SIsMember(ctx, key, member interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SIsMember", reflect.TypeOf((*MockClientInterface)(nil).SIsMember), ctx, key, member)
 }
 
+// Set mocks base method.
+func (m *MockClientInterface) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, key, value, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockClientInterfaceMockRecorder) Set(ctx, key, value, expiration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockClientInterface)(nil).Set), ctx, key, value, expiration)
+}
+
 // SMembers mocks base method.
 func (m *MockClientInterface) SMembers(ctx context.Context, key string) ([]string, error) {
 	m.ctrl.T.Helper()
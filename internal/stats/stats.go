@@ -41,7 +41,14 @@ func (s *Stats) IncrementTotalPages() {
 	s.TotalPages++
 }
 
-// GetMatchedLinks retrieves the total number of not matched links.
+// GetTotalLinks retrieves the total number of links found.
+func (s *Stats) GetTotalLinks() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.TotalLinks
+}
+
+// GetMatchedLinks retrieves the total number of matched links.
 func (s *Stats) GetMatchedLinks() int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -3,8 +3,11 @@ package tasks
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/hibiken/asynq"
+	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/internal/termmatcher"
 )
 
 // AsynqClient defines an interface with the methods you use from asynq.Client.
@@ -17,13 +20,36 @@ const (
 )
 
 type CrawlTaskPayload struct {
-	URL         string `json:"url"`
-	SearchTerms string `json:"search_terms"`
-	CrawlSiteID string `json:"crawl_site_id"`
-	MaxDepth    int    `json:"max_depth"`
-	Debug       bool   `json:"debug"`
+	URL                   string   `json:"url"`
+	StartURLs             []string `json:"start_urls,omitempty"`
+	AllowedDomains        []string `json:"allowed_domains,omitempty"`
+	SearchTerms           string   `json:"search_terms"`
+	CrawlSiteID           string   `json:"crawl_site_id"`
+	MaxDepth              int      `json:"max_depth"`
+	Debug                 bool     `json:"debug"`
+	MaxConcurrentRequests int      `json:"max_concurrent_requests,omitempty"`
+	DelayBetweenRequests  string   `json:"delay_between_requests,omitempty"`
+	RandomDelay           string   `json:"random_delay,omitempty"`
+	DiscoverFeeds         bool     `json:"discover_feeds,omitempty"`
+	DomainLimits          []string `json:"domain_limits,omitempty"`
+	Exclude               []string `json:"exclude,omitempty"`
+	Feeds                 []string `json:"feeds,omitempty"`
+	Include               []string `json:"include,omitempty"`
+	Incremental           bool     `json:"incremental,omitempty"`
+	Language              string   `json:"language,omitempty"`
+	MaxDuration           string   `json:"max_duration,omitempty"`
+	MaxPages              int      `json:"max_pages,omitempty"`
+	MatchContent          bool     `json:"match_content,omitempty"`
+	Matchers              []string `json:"matchers,omitempty"`
+	Query                 string   `json:"query,omitempty"`
+	SimilarityMetric      string   `json:"similarity_metric,omitempty"`
+	SimilarityThreshold   float64  `json:"similarity_threshold,omitempty"`
 }
 
+// timeoutMargin is added to MaxDuration for the Asynq task timeout, so that a
+// crawl has time to save its results after its budget runs out.
+const timeoutMargin = time.Minute
+
 // EnqueueCrawlTask creates asynq task
 func EnqueueCrawlTask(client AsynqClient, payload *CrawlTaskPayload) (string, error) {
 	task, err := NewCrawlTask(payload)
@@ -38,21 +64,129 @@ func EnqueueCrawlTask(client AsynqClient, payload *CrawlTaskPayload) (string, er
 }
 
 func NewCrawlTask(payload *CrawlTaskPayload) (*asynq.Task, error) {
-	// Validate the payload
-	if payload.URL == "" || payload.SearchTerms == "" || payload.CrawlSiteID == "" || payload.MaxDepth < 0 {
-		return nil, fmt.Errorf("invalid payload")
+	if err := payload.Validate(); err != nil {
+		return nil, err
 	}
 
-	data, err := json.Marshal(map[string]interface{}{
-		"url":           payload.URL,
-		"search_terms":  payload.SearchTerms,
-		"crawl_site_id": payload.CrawlSiteID,
-		"max_depth":     payload.MaxDepth,
-		"debug":         payload.Debug,
-	})
+	data, err := json.Marshal(payload)
 	if err != nil {
 		return nil, err
 	}
 
-	return asynq.NewTask(CrawlTaskType, data), nil
+	// Let crawls run past Asynq's default timeout when asked to
+	var opts []asynq.Option
+	if maxDuration, _ := payload.maxDuration(); maxDuration > 0 {
+		opts = append(opts, asynq.Timeout(maxDuration+timeoutMargin))
+	}
+
+	return asynq.NewTask(CrawlTaskType, data, opts...), nil
+}
+
+// Validate checks that the payload describes a crawl that can be run, with
+// crawler.CrawlOptions.Validate. A payload without search terms, a query or
+// matchers uses the query saved for its site.
+func (p *CrawlTaskPayload) Validate() error {
+	options, err := p.options()
+	if err != nil {
+		return fmt.Errorf("invalid payload: %v", err)
+	}
+	if err := options.Validate(); err != nil {
+		return fmt.Errorf("invalid payload: %v", err)
+	}
+	return nil
+}
+
+func (p *CrawlTaskPayload) maxDuration() (time.Duration, error) {
+	if p.MaxDuration == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(p.MaxDuration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max_duration: %v", err)
+	}
+	return d, nil
+}
+
+// CrawlOptions returns the options for the crawl described by the payload.
+func (p *CrawlTaskPayload) CrawlOptions() (crawler.CrawlOptions, error) {
+	if err := p.Validate(); err != nil {
+		return crawler.CrawlOptions{}, err
+	}
+	return p.options()
+}
+
+// options returns the options of the payload, without checking them.
+func (p *CrawlTaskPayload) options() (crawler.CrawlOptions, error) {
+	options, err := p.RateLimits()
+	if err != nil {
+		return options, err
+	}
+
+	options.CrawlSiteID = p.CrawlSiteID
+	options.StartURL = p.URL
+	options.StartURLs = p.StartURLs
+	options.AllowedDomains = p.AllowedDomains
+	options.MaxDepth = p.MaxDepth
+	options.MaxPages = p.MaxPages
+	options.DiscoverFeeds = p.DiscoverFeeds
+	options.Exclude = p.Exclude
+	options.Feeds = p.Feeds
+	options.Include = p.Include
+	options.Incremental = p.Incremental
+	options.Language = p.Language
+	options.MatchContent = p.MatchContent
+	options.Matchers = p.Matchers
+	options.Query = p.Query
+	options.SimilarityMetric = p.SimilarityMetric
+	options.SimilarityThreshold = p.SimilarityThreshold
+	options.Debug = p.Debug
+
+	options.MaxDuration, err = p.maxDuration()
+	if err != nil {
+		return options, err
+	}
+
+	options.SearchTerms = termmatcher.ParseSearchTerms(p.SearchTerms)
+
+	return options, nil
+}
+
+// RateLimits returns the rate limiting options of the payload as CrawlOptions.
+// A payload without max_concurrent_requests or delay_between_requests uses
+// crawler.DefaultParallelism or crawler.DefaultDelay, "0s" turns the delay
+// off.
+func (p *CrawlTaskPayload) RateLimits() (crawler.CrawlOptions, error) {
+	options := crawler.CrawlOptions{
+		MaxConcurrentRequests: p.MaxConcurrentRequests,
+		DelayBetweenRequests:  crawler.DefaultDelay,
+	}
+
+	if p.MaxConcurrentRequests < 0 {
+		return options, fmt.Errorf("max_concurrent_requests must not be negative")
+	}
+	if p.MaxConcurrentRequests == 0 {
+		options.MaxConcurrentRequests = crawler.DefaultParallelism
+	}
+
+	var err error
+	if p.DelayBetweenRequests != "" {
+		options.DelayBetweenRequests, err = time.ParseDuration(p.DelayBetweenRequests)
+		if err != nil {
+			return options, fmt.Errorf("invalid delay_between_requests: %v", err)
+		}
+	}
+
+	if p.RandomDelay != "" {
+		options.RandomDelay, err = time.ParseDuration(p.RandomDelay)
+		if err != nil {
+			return options, fmt.Errorf("invalid random_delay: %v", err)
+		}
+	}
+
+	options.DomainLimits, err = crawler.ParseDomainLimits(p.DomainLimits)
+	if err != nil {
+		return options, err
+	}
+
+	return options, nil
 }
@@ -0,0 +1,220 @@
+package termmatcher
+
+import (
+	"strings"
+
+	"github.com/jonesrussell/page-prowler/internal/matcher"
+	"github.com/jonesrussell/page-prowler/utils"
+)
+
+// Explanation reports each step of matching a link, for tuning search terms
+// without crawling.
+type Explanation struct {
+	URL        string          `json:"url"`
+	Slug       TextExplanation `json:"slug"`
+	AnchorText TextExplanation `json:"anchor_text"`
+
+	// Content is the processed slug and anchor text the terms are matched in
+	Content  string `json:"content"`
+	TooShort bool   `json:"too_short,omitempty"`
+
+	Language  string  `json:"language"`
+	Metric    string  `json:"metric"`
+	Threshold float64 `json:"threshold"`
+
+	Query        string             `json:"query,omitempty"`
+	QueryMatched bool               `json:"query_matched,omitempty"`
+	Terms        []TermExplanation  `json:"terms"`
+	Topics       []TopicExplanation `json:"topics,omitempty"`
+
+	MatchingTerms []string `json:"matching_terms"`
+	Matched       bool     `json:"matched"`
+}
+
+// TextExplanation is a text as it goes through processing.
+type TextExplanation struct {
+	Text             string `json:"text"`
+	WithoutStopwords string `json:"without_stopwords"`
+	Stemmed          string `json:"stemmed"`
+}
+
+// TermExplanation reports how a search term compares with the content.
+type TermExplanation struct {
+	Term       string  `json:"term"`
+	Processed  string  `json:"processed"`
+	Quoted     bool    `json:"quoted,omitempty"`
+	ExactMatch bool    `json:"exact_match"`
+	Score      float64 `json:"score"`                // the best similarity with the content
+	BestMatch  string  `json:"best_match,omitempty"` // the content the score is for
+	Excluded   bool    `json:"excluded,omitempty"`   // the term is excluded by the query
+	Matched    bool    `json:"matched"`
+}
+
+// TopicExplanation reports whether a topic matcher matches the content.
+type TopicExplanation struct {
+	Name    string `json:"name"`
+	Matched bool   `json:"matched"`
+}
+
+// Explain matches a link, given by its URL and anchor text, against
+// searchTerms and reports every step.
+func (tm *TermMatcher) Explain(href string, anchorText string, searchTerms []string) *Explanation {
+	e := tm.explainLink(href, anchorText)
+
+	for _, term := range tm.parseSearchTerms(searchTerms) {
+		e.Terms = append(e.Terms, tm.explainTerm(term, e.Content))
+	}
+
+	e.MatchingTerms = tm.GetMatchingTerms(href, anchorText, searchTerms)
+	e.Matched = len(e.MatchingTerms) > 0
+	return e
+}
+
+// ExplainQuery matches a link, given by its URL and anchor text, against
+// query and reports every step.
+func (tm *TermMatcher) ExplainQuery(href string, anchorText string, query *Query) *Explanation {
+	e := tm.explainLink(href, anchorText)
+	e.Query = query.String()
+
+	included := make(map[string]bool)
+	for _, term := range query.Terms() {
+		included[term] = true
+	}
+
+	found := make(map[string]bool)
+	for _, text := range queryTermTexts(query) {
+		term, ok := tm.newSearchTerm(text)
+		if !ok {
+			continue
+		}
+		explanation := tm.explainTerm(term, e.Content)
+		explanation.Excluded = !included[text]
+		found[text] = explanation.Matched
+		e.Terms = append(e.Terms, explanation)
+	}
+
+	e.QueryMatched = !e.TooShort && query.Match(func(term string) bool { return found[term] })
+	e.MatchingTerms = tm.GetQueryMatchingTerms(href, anchorText, query)
+	e.Matched = len(e.MatchingTerms) > 0
+	return e
+}
+
+// explainLink explains the processing of a link and the topics it matches.
+func (tm *TermMatcher) explainLink(href string, anchorText string) *Explanation {
+	e := &Explanation{
+		URL:        href,
+		Slug:       tm.explainText(utils.ExtractLastSegmentFromURL(href)),
+		AnchorText: tm.explainText(anchorText),
+		Content:    tm.linkContent(href, anchorText),
+		Language:   tm.language,
+		Metric:     tm.similarity.Name,
+		Threshold:  tm.similarity.Threshold,
+	}
+	e.TooShort = len(e.Content) < minTitleLength
+
+	for _, m := range tm.matchers {
+		named, ok := m.(matcher.NamedMatcher)
+		if !ok {
+			continue
+		}
+		matched, _ := m.Match(e.Content, "")
+		e.Topics = append(e.Topics, TopicExplanation{Name: named.Name(), Matched: matched && !e.TooShort})
+	}
+
+	return e
+}
+
+// explainText explains the processing of text.
+func (tm *TermMatcher) explainText(text string) TextExplanation {
+	return TextExplanation{
+		Text:             text,
+		WithoutStopwords: tm.removeStopwords(text),
+		Stemmed:          tm.processContent(text),
+	}
+}
+
+// explainTerm compares term with processed content as matches does, keeping
+// the best similarity found.
+func (tm *TermMatcher) explainTerm(term searchTerm, content string) TermExplanation {
+	e := TermExplanation{
+		Term:      term.text,
+		Processed: strings.Join(term.words, " "),
+		Quoted:    term.exact,
+	}
+	if len(content) < minTitleLength {
+		return e
+	}
+
+	words := tm.contentWords(content)
+
+	compare := func(text string) {
+		if score := tm.similarity.Compare(e.Processed, text); score > e.Score || e.BestMatch == "" {
+			e.Score = score
+			e.BestMatch = text
+		}
+	}
+
+	if len(term.words) == 1 && !term.exact {
+		for _, word := range words {
+			if word == e.Processed {
+				e.ExactMatch = true
+			}
+		}
+		if tm.similarity.IsLocal() {
+			compare(strings.Join(words, " "))
+		} else {
+			for _, word := range words {
+				compare(word)
+			}
+		}
+	} else {
+		n := len(term.words)
+		for i := 0; i+n <= len(words); i++ {
+			window := words[i : i+n]
+			if equalWords(window, term.words) {
+				e.ExactMatch = true
+			}
+			text := strings.Join(window, " ")
+			if term.exact || (tm.similarity.IsLocal() && len(text) < len(e.Processed)) {
+				continue
+			}
+			compare(text)
+		}
+	}
+
+	e.Matched = e.ExactMatch || (!term.exact && e.BestMatch != "" && e.Score >= tm.similarity.Threshold)
+	return e
+}
+
+// queryTermTexts returns every term of query, excluded or not, in the order
+// they are written.
+func queryTermTexts(query *Query) []string {
+	var terms []string
+	seen := make(map[string]bool)
+
+	var walk func(node queryNode)
+	walk = func(node queryNode) {
+		switch n := node.(type) {
+		case termNode:
+			if !seen[n.term] {
+				seen[n.term] = true
+				terms = append(terms, n.term)
+			}
+		case notNode:
+			walk(n.node)
+		case andNode:
+			for _, child := range n.nodes {
+				walk(child)
+			}
+		case orNode:
+			for _, child := range n.nodes {
+				walk(child)
+			}
+		case requiredNode:
+			walk(n.node)
+		}
+	}
+	walk(query.root)
+
+	return terms
+}
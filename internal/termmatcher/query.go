@@ -0,0 +1,358 @@
+package termmatcher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidQuery is returned for queries that cannot be parsed.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Query is a boolean search query, such as
+//
+//	(opioid OR fentanyl) AND toronto -sports
+//
+// Terms next to each other must all match, as if joined by AND. OR matches
+// either side, NOT or a leading "-" excludes a term or group, and parentheses
+// group. A leading "+" makes an alternative of OR required: "fire OR +flood"
+// only matches when flood does, and fire is reported when it is found too.
+// A comma is the same as OR, so a list of single word search terms is a
+// valid query. Double quotes match a phrase exactly. The operators are only
+// recognised in upper case.
+type Query struct {
+	text string
+	root queryNode
+}
+
+// queryNode is a node of the syntax tree of a query. match reports whether
+// the node matches, given whether each term is in the content.
+type queryNode interface {
+	match(found func(term string) bool) bool
+	String() string
+}
+
+type termNode struct{ term string }
+
+type notNode struct{ node queryNode }
+
+type andNode struct{ nodes []queryNode }
+
+type orNode struct{ nodes []queryNode }
+
+type requiredNode struct{ node queryNode }
+
+func (n termNode) match(found func(string) bool) bool { return found(n.term) }
+
+func (n notNode) match(found func(string) bool) bool { return !n.node.match(found) }
+
+func (n andNode) match(found func(string) bool) bool {
+	for _, node := range n.nodes {
+		if !node.match(found) {
+			return false
+		}
+	}
+	return true
+}
+
+// match reports whether any node matches or, when some are required, whether
+// every required node does.
+func (n orNode) match(found func(string) bool) bool {
+	required, matched := false, false
+	for _, node := range n.nodes {
+		if _, ok := node.(requiredNode); ok {
+			if !node.match(found) {
+				return false
+			}
+			required = true
+		} else if !matched {
+			matched = node.match(found)
+		}
+	}
+	return required || matched
+}
+
+func (n requiredNode) match(found func(string) bool) bool { return n.node.match(found) }
+
+func (n termNode) String() string { return n.term }
+
+func (n notNode) String() string { return "-" + n.node.String() }
+
+func (n andNode) String() string { return joinNodes(n.nodes, " AND ") }
+
+func (n orNode) String() string { return joinNodes(n.nodes, " OR ") }
+
+func (n requiredNode) String() string { return "+" + n.node.String() }
+
+func joinNodes(nodes []queryNode, sep string) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = node.String()
+	}
+	return "(" + strings.Join(parts, sep) + ")"
+}
+
+// ParseQuery parses a boolean search query.
+func ParseQuery(text string) (*Query, error) {
+	tokens, err := tokenizeQuery(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidQuery, text, err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w %q: no terms", ErrInvalidQuery, text)
+	}
+
+	p := &queryParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidQuery, text, err)
+	}
+
+	query := &Query{text: strings.TrimSpace(text), root: root}
+	if len(query.Terms()) == 0 {
+		return nil, fmt.Errorf("%w %q: every term is excluded", ErrInvalidQuery, text)
+	}
+
+	return query, nil
+}
+
+// String returns the query as it was written.
+func (q *Query) String() string {
+	return q.text
+}
+
+// Terms returns the terms of the query that are not excluded, in the order
+// they are written.
+func (q *Query) Terms() []string {
+	var terms []string
+	seen := make(map[string]bool)
+
+	var walk func(node queryNode, excluded bool)
+	walk = func(node queryNode, excluded bool) {
+		switch n := node.(type) {
+		case termNode:
+			if !excluded && !seen[n.term] {
+				seen[n.term] = true
+				terms = append(terms, n.term)
+			}
+		case notNode:
+			walk(n.node, !excluded)
+		case andNode:
+			for _, child := range n.nodes {
+				walk(child, excluded)
+			}
+		case orNode:
+			for _, child := range n.nodes {
+				walk(child, excluded)
+			}
+		case requiredNode:
+			walk(n.node, excluded)
+		}
+	}
+	walk(q.root, false)
+
+	return terms
+}
+
+// Match reports whether the query matches, given whether each of its terms
+// is in the content.
+func (q *Query) Match(found func(term string) bool) bool {
+	return q.root.match(found)
+}
+
+type queryTokenKind int
+
+const (
+	tokenTerm queryTokenKind = iota
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenRequired
+	tokenOpen
+	tokenClose
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+}
+
+// tokenizeQuery splits a query into terms, quoted phrases and operators.
+func tokenizeQuery(text string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{tokenOpen, "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{tokenClose, ")"})
+			i++
+		case r == ',':
+			tokens = append(tokens, queryToken{tokenOr, ","})
+			i++
+		case r == '-' || r == '+':
+			// Only a prefix, "covid-19" is a single term
+			if i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+				kind := tokenNot
+				if r == '+' {
+					kind = tokenRequired
+				}
+				tokens = append(tokens, queryToken{kind, string(r)})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("%q must be followed by a term", string(r))
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			if phrase := strings.TrimSpace(string(runes[i+1 : end])); phrase != "" {
+				tokens = append(tokens, queryToken{tokenTerm, `"` + phrase + `"`})
+			}
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()",`, runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			switch word {
+			case "AND":
+				tokens = append(tokens, queryToken{tokenAnd, word})
+			case "OR":
+				tokens = append(tokens, queryToken{tokenOr, word})
+			case "NOT":
+				tokens = append(tokens, queryToken{tokenNot, word})
+			default:
+				tokens = append(tokens, queryToken{tokenTerm, word})
+			}
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+// queryParser is a recursive descent parser for the grammar
+//
+//	or    = and { ("OR" | ",") and }
+//	and   = unary { ["AND"] unary }
+//	unary = ("NOT" | "-") unary | "+" unary | "(" or ")" | term
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos], true
+	}
+	return queryToken{}, false
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []queryNode{node}
+	for {
+		token, ok := p.peek()
+		if !ok || token.kind != tokenOr {
+			break
+		}
+		p.pos++
+
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return orNode{nodes}, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	node, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []queryNode{node}
+	for {
+		token, ok := p.peek()
+		if !ok || token.kind == tokenOr || token.kind == tokenClose {
+			break
+		}
+		if token.kind == tokenAnd {
+			p.pos++
+		}
+
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return andNode{nodes}, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	token, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("missing term at the end")
+	}
+	p.pos++
+
+	switch token.kind {
+	case tokenNot:
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	case tokenRequired:
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return requiredNode{node}, nil
+	case tokenOpen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if token, ok := p.peek(); !ok || token.kind != tokenClose {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+	case tokenTerm:
+		return termNode{token.text}, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", token.text)
+	}
+}
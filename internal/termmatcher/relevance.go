@@ -0,0 +1,151 @@
+package termmatcher
+
+import (
+	"math"
+	"strings"
+
+	"github.com/jonesrussell/page-prowler/internal/matcher"
+	"github.com/jonesrussell/page-prowler/models"
+)
+
+// locationWeights is how much a match counts for where it is found. A term in
+// the title says more about a page than one in its body.
+var locationWeights = map[string]float64{
+	models.MatchLocationTitle:  1,
+	models.MatchLocationURL:    0.9,
+	models.MatchLocationAnchor: 0.8,
+	models.MatchLocationBody:   0.6,
+}
+
+// Weights of the parts of the relevance score, adding up to 1.
+const (
+	locationWeight  = 0.35
+	coverageWeight  = 0.25
+	frequencyWeight = 0.2
+	phraseWeight    = 0.1
+	topicWeight     = 0.1
+)
+
+// saturatingFrequency is the number of occurrences of the terms that scores
+// the whole frequency part of the relevance.
+const saturatingFrequency = 10
+
+// relevanceSignals are what the relevance of a match is scored on.
+type relevanceSignals struct {
+	locations   []string // where the terms were found
+	terms       int      // distinct terms found
+	occurrences int      // occurrences of all the terms
+	phrases     int      // terms of several words found
+	topicScore  float64  // weights of the topic terms found
+}
+
+// score combines the signals into a relevance between 0 and 1.
+func (s relevanceSignals) score() float64 {
+	location := 0.0
+	for _, l := range s.locations {
+		location = math.Max(location, locationWeights[l])
+	}
+	// Terms found in several places count a little more
+	if len(s.locations) > 1 {
+		location = math.Min(1, location+0.05*float64(len(s.locations)-1))
+	}
+
+	coverage := 1 - math.Pow(0.5, float64(s.terms))
+	frequency := math.Min(1, math.Log1p(float64(s.occurrences))/math.Log1p(saturatingFrequency))
+	phrase := math.Min(1, float64(s.phrases)/2)
+	topic := 1 - math.Pow(0.5, s.topicScore)
+
+	score := locationWeight*location +
+		coverageWeight*coverage +
+		frequencyWeight*frequency +
+		phraseWeight*phrase +
+		topicWeight*topic
+	return math.Round(score*1000) / 1000
+}
+
+// Relevance scores how relevant a match is, from 0 to 1, given the texts it
+// was matched in, by location such as models.MatchLocationURL, and the
+// matching terms found in them. The score grows with the importance of the
+// locations the terms are in, the number of distinct terms, how often they
+// occur, the phrases among them and the weights of the topics matched.
+func (tm *TermMatcher) Relevance(texts map[string]string, matchingTerms []string) float64 {
+	var signals relevanceSignals
+
+	topics := make(map[string]matcher.Matcher)
+	for _, m := range tm.matchers {
+		if named, ok := m.(matcher.NamedMatcher); ok {
+			topics[named.Name()] = m
+		}
+	}
+
+	words := make(map[string][]string, len(texts))
+	for location, text := range texts {
+		words[location] = tm.contentWords(tm.processContent(text))
+	}
+
+	found := make(map[string]bool)
+	for _, term := range matchingTerms {
+		if m, ok := topics[term]; ok {
+			signals.terms++
+			for location, text := range texts {
+				if scorer, ok := m.(matcher.Scorer); ok {
+					signals.topicScore = math.Max(signals.topicScore, scorer.Score(text))
+				}
+				if matched, _ := m.Match(tm.processContent(text), ""); matched {
+					found[location] = true
+					signals.occurrences++
+				}
+			}
+			continue
+		}
+
+		searchTerm, ok := tm.newSearchTerm(term)
+		if !ok {
+			continue
+		}
+		signals.terms++
+		if len(searchTerm.words) > 1 {
+			signals.phrases++
+		}
+		for location, content := range words {
+			if n := tm.count(searchTerm, content); n > 0 {
+				found[location] = true
+				signals.occurrences += n
+			}
+		}
+	}
+
+	for location := range found {
+		signals.locations = append(signals.locations, location)
+	}
+
+	return signals.score()
+}
+
+// count returns how many times term occurs in content, the words of processed
+// content returned by contentWords, matching as matches does.
+func (tm *TermMatcher) count(term searchTerm, content []string) int {
+	phrase := strings.Join(term.words, " ")
+	n := len(term.words)
+
+	count := 0
+	for i := 0; i+n <= len(content); i++ {
+		window := content[i : i+n]
+		if equalWords(window, term.words) {
+			count++
+			continue
+		}
+		if term.exact {
+			continue
+		}
+
+		text := strings.Join(window, " ")
+		if n > 1 && tm.similarity.IsLocal() && len(text) < len(phrase) {
+			continue
+		}
+		if tm.similarity.Match(phrase, text) {
+			count++
+		}
+	}
+	return count
+}
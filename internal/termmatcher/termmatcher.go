@@ -4,43 +4,96 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/adrg/strutil"
-	"github.com/adrg/strutil/metrics"
-	"github.com/bbalet/stopwords"
-	"github.com/caneroj1/stemmer"
 	"github.com/jonesrussell/loggo"
+	"github.com/jonesrussell/page-prowler/internal/language"
 	"github.com/jonesrussell/page-prowler/internal/matcher" // Import the matcher interface
 	"github.com/jonesrussell/page-prowler/utils"
 )
 
 const (
-	minTitleLength      = 5
-	similarityThreshold = 0.9
+	minTitleLength = 5
 )
 
 type TermMatcher struct {
-	logger   loggo.LoggerInterface
-	swg      *metrics.SmithWatermanGotoh
-	matchers []matcher.Matcher // List of matchers
+	logger     loggo.LoggerInterface
+	similarity matcher.Similarity
+	language   string            // the language content and terms are processed in
+	matchers   []matcher.Matcher // List of matchers
 }
 
 func NewTermMatcher(logger loggo.LoggerInterface, matchers []matcher.Matcher) *TermMatcher {
-	swg := metrics.NewSmithWatermanGotoh()
-	swg.CaseSensitive = false
-	swg.GapPenalty = -0.1
-	swg.Substitution = metrics.MatchMismatch{
-		Match:    1,
-		Mismatch: -0.5,
-	}
+	similarity, _ := matcher.NewSimilarity(matcher.MetricSmithWatermanGotoh, 0)
 
 	return &TermMatcher{
-		logger:   logger,
-		swg:      swg,
-		matchers: matchers,
+		logger:     logger,
+		similarity: similarity,
+		language:   language.Default,
+		matchers:   matchers,
+	}
+}
+
+// WithSimilarity returns a copy of the TermMatcher that compares terms with
+// similarity, leaving the TermMatcher itself as it is.
+func (tm *TermMatcher) WithSimilarity(similarity matcher.Similarity) *TermMatcher {
+	copied := *tm
+	copied.similarity = similarity
+	return &copied
+}
+
+// WithMatchers returns a copy of the TermMatcher that also matches content
+// with matchers, leaving the TermMatcher itself as it is.
+func (tm *TermMatcher) WithMatchers(matchers []matcher.Matcher) *TermMatcher {
+	copied := *tm
+	copied.matchers = append(append([]matcher.Matcher{}, tm.matchers...), matchers...)
+	return &copied
+}
+
+// WithLanguage returns a copy of the TermMatcher that removes the stopwords
+// of lang and stems in lang, leaving the TermMatcher itself as it is.
+// Unsupported languages are processed as the default, English.
+func (tm *TermMatcher) WithLanguage(lang string) *TermMatcher {
+	copied := *tm
+	copied.language = language.Normalize(lang)
+	if copied.language == "" {
+		copied.language = language.Default
 	}
+	return &copied
+}
+
+// Language returns the language content and terms are processed in.
+func (tm *TermMatcher) Language() string {
+	return tm.language
+}
+
+// Similarity returns the metric and threshold terms are compared with.
+func (tm *TermMatcher) Similarity() matcher.Similarity {
+	return tm.similarity
 }
 
 func (tm *TermMatcher) GetMatchingTerms(href string, anchorText string, searchTerms []string) []string {
+	return tm.matchContent(tm.linkContent(href, anchorText), searchTerms)
+}
+
+// GetMatchingTermsInText returns the search terms found in text, such as the
+// title or the body of a page.
+func (tm *TermMatcher) GetMatchingTermsInText(text string, searchTerms []string) []string {
+	return tm.matchContent(tm.processContent(text), searchTerms)
+}
+
+// GetQueryMatchingTerms returns the terms of query found in the last segment
+// of href and the anchor text of a link, or none if they do not match query.
+func (tm *TermMatcher) GetQueryMatchingTerms(href string, anchorText string, query *Query) []string {
+	return tm.matchQuery(tm.linkContent(href, anchorText), query)
+}
+
+// GetQueryMatchingTermsInText returns the terms of query found in text, or
+// none if it does not match query.
+func (tm *TermMatcher) GetQueryMatchingTermsInText(text string, query *Query) []string {
+	return tm.matchQuery(tm.processContent(text), query)
+}
+
+// linkContent returns the processed last segment of href and anchor text.
+func (tm *TermMatcher) linkContent(href string, anchorText string) string {
 	content := utils.ExtractLastSegmentFromURL(href)
 	processedContent := tm.processContent(content)
 	tm.logger.Debug(fmt.Sprintf("Processed content from URL: %v", processedContent))
@@ -51,31 +104,81 @@ func (tm *TermMatcher) GetMatchingTerms(href string, anchorText string, searchTe
 	combinedContent := tm.combineContents(processedContent, anchorContent)
 	tm.logger.Debug(fmt.Sprintf("Combined content: %v", combinedContent))
 
-	if len(combinedContent) < minTitleLength {
-		tm.logger.Debug(fmt.Sprintf("Combined content is less than minimum title length: %d", minTitleLength))
+	return combinedContent
+}
+
+// matchQuery returns the terms of query found in processed content, or none
+// if the content does not match query.
+func (tm *TermMatcher) matchQuery(content string, query *Query) []string {
+	if len(content) < minTitleLength {
+		tm.logger.Debug(fmt.Sprintf("Content is less than minimum title length: %d", minTitleLength))
 		return []string{}
 	}
 
-	var allSearchTerms []string
-	for _, terms := range searchTerms {
-		allSearchTerms = append(allSearchTerms, strings.Split(terms, ",")...)
+	words := tm.contentWords(content)
+
+	found := make(map[string]bool)
+	isFound := func(term string) bool {
+		if matched, ok := found[term]; ok {
+			return matched
+		}
+		searchTerm, ok := tm.newSearchTerm(term)
+		found[term] = ok && tm.matches(searchTerm, words)
+		return found[term]
+	}
+
+	// Topics match on their own, next to the query
+	result := tm.topicMatchingTerms(content)
+
+	if !query.Match(isFound) {
+		tm.logger.Debug(fmt.Sprintf("Query %q does not match", query))
+		if len(result) == 0 {
+			return []string{}
+		}
+		return result
+	}
+
+	for _, term := range query.Terms() {
+		if isFound(term) {
+			searchTerm, _ := tm.newSearchTerm(term)
+			result = append(result, searchTerm.text)
+		}
 	}
 
+	tm.logger.Debug(fmt.Sprintf("Query matching terms result: %v", result))
+	return result
+}
+
+// matchContent returns the search terms found in processed content.
+func (tm *TermMatcher) matchContent(content string, searchTerms []string) []string {
+	if len(content) < minTitleLength {
+		tm.logger.Debug(fmt.Sprintf("Content is less than minimum title length: %d", minTitleLength))
+		return []string{}
+	}
+
+	terms := tm.parseSearchTerms(searchTerms)
+
 	// Check each matcher for matches
 	var matchingTerms []string
 	for _, m := range tm.matchers {
-		matched, err := m.Match(combinedContent, "")
+		matched, err := m.Match(content, "")
 		if err != nil {
 			tm.logger.Error("Error matching term", err)
 			continue // Skip to the next matcher if there's an error
 		}
 		if matched {
-			matchingTerms = append(matchingTerms, allSearchTerms...) // Add search terms if matched
+			if named, ok := m.(matcher.NamedMatcher); ok {
+				matchingTerms = append(matchingTerms, named.Name()) // Add the topic if matched
+				continue
+			}
+			for _, term := range terms {
+				matchingTerms = append(matchingTerms, term.text) // Add search terms if matched
+			}
 		}
 	}
 
 	// Use findMatchingTerms to check for additional matches
-	matchingTerms = append(matchingTerms, tm.findMatchingTerms(combinedContent, allSearchTerms)...)
+	matchingTerms = append(matchingTerms, tm.findMatchingTerms(content, terms)...)
 
 	// Remove duplicates
 	seen := make(map[string]bool)
@@ -97,23 +200,39 @@ func (tm *TermMatcher) GetMatchingTerms(href string, anchorText string, searchTe
 	return result
 }
 
-func (tm *TermMatcher) findMatchingTerms(content string, searchTerms []string) []string {
+// topicMatchingTerms returns the names of the topic matchers that match
+// processed content.
+func (tm *TermMatcher) topicMatchingTerms(content string) []string {
+	var result []string
+	for _, m := range tm.matchers {
+		named, ok := m.(matcher.NamedMatcher)
+		if !ok {
+			continue
+		}
+		matched, err := m.Match(content, "")
+		if err != nil {
+			tm.logger.Error("Error matching topic", err)
+			continue
+		}
+		if matched {
+			result = append(result, named.Name())
+		}
+	}
+	return result
+}
+
+// findMatchingTerms returns the search terms, as they were given, that are
+// in the processed content.
+func (tm *TermMatcher) findMatchingTerms(content string, searchTerms []searchTerm) []string {
 	var matchingTerms []string
 
-	content = tm.convertToLowercase(content)
-	contentStemmed := tm.stemContent(content)
+	words := tm.contentWords(content)
 
-	tm.logger.Debug(fmt.Sprintf("Stemmed content: %v", contentStemmed))
+	tm.logger.Debug(fmt.Sprintf("Stemmed content: %v", strings.Join(words, " ")))
 
 	for _, searchTerm := range searchTerms {
-		searchTerm = tm.convertToLowercase(searchTerm)
-		searchTermStemmed := searchTerm // Avoid stemming the search term
-
-		words := strings.Fields(searchTermStemmed)
-		for _, word := range words {
-			if tm.compareAndAppendTerm(word, contentStemmed) {
-				matchingTerms = append(matchingTerms, word)
-			}
+		if tm.matches(searchTerm, words) {
+			matchingTerms = append(matchingTerms, searchTerm.text)
 		}
 	}
 
@@ -127,18 +246,23 @@ func (tm *TermMatcher) findMatchingTerms(content string, searchTerms []string) [
 
 // CompareTerms  method
 func (tm *TermMatcher) CompareTerms(term1, term2 string) float64 {
-	return strutil.Similarity(term1, term2, tm.swg)
+	return tm.similarity.Compare(term1, term2)
+}
+
+// contentWords returns the words of processed content that terms are matched
+// and counted against.
+func (tm *TermMatcher) contentWords(content string) []string {
+	return strings.Fields(tm.stemContent(tm.convertToLowercase(content)))
 }
 
 func (tm *TermMatcher) processContent(content string) string {
-	content = strings.ReplaceAll(content, "-", " ")                         // Remove hyphens
-	content = strings.TrimSpace(stopwords.CleanString(content, "en", true)) // Remove stopwords
+	return language.Process(content, tm.language)
+}
 
-	// Process and stem
-	content = strings.ToLower(content)
-	words := strings.Fields(content)
-	words = stemmer.StemMultiple(words)
-	return strings.ToLower(strings.Join(words, " "))
+// removeStopwords removes hyphens and stopwords, the first step of
+// processContent.
+func (tm *TermMatcher) removeStopwords(content string) string {
+	return language.RemoveStopwords(content, tm.language)
 }
 
 func (tm *TermMatcher) combineContents(content1 string, content2 string) string {
@@ -158,11 +282,17 @@ func (tm *TermMatcher) compareAndAppendTerm(searchTerm string, content string) b
 		}
 	}
 
-	// If no exact match, use SWG for comparison
-	similarity := tm.CompareTerms(searchTerm, content)
-	if similarity >= similarityThreshold { // Use constant for threshold
-		tm.logger.Debug(fmt.Sprintf("Matching term found: %v", searchTerm))
-		return true
+	// If no exact match, compare by similarity. Smith-Waterman-Gotoh finds
+	// the term within the content, the other metrics compare whole strings
+	// so the term is compared with each word.
+	if tm.similarity.IsLocal() {
+		words = []string{content}
+	}
+	for _, word := range words {
+		if tm.similarity.Match(searchTerm, word) {
+			tm.logger.Debug(fmt.Sprintf("Matching term found: %v", searchTerm))
+			return true
+		}
 	}
 	return false
 }
@@ -172,11 +302,5 @@ func (tm *TermMatcher) convertToLowercase(content string) string {
 }
 
 func (tm *TermMatcher) stemContent(content string) string {
-	words := strings.Fields(content)
-	stemmedWords := stemmer.StemMultiple(words)
-	lowercaseStemmedWords := make([]string, len(stemmedWords))
-	for i, word := range stemmedWords {
-		lowercaseStemmedWords[i] = strings.ToLower(word)
-	}
-	return strings.Join(lowercaseStemmedWords, " ")
+	return strings.Join(language.Stem(strings.Fields(content), tm.language), " ")
 }
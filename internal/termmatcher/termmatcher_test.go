@@ -1,19 +1,21 @@
 package termmatcher
 
 import (
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
 
-	"github.com/adrg/strutil/metrics"
 	"github.com/golang/mock/gomock"
 	"github.com/jonesrussell/loggo"
 	"github.com/jonesrussell/page-prowler/internal/matcher"
+	"github.com/jonesrussell/page-prowler/models"
+	"github.com/stretchr/testify/assert"
 )
 
 type fields struct {
-	logger loggo.LoggerInterface
-	swg    *metrics.SmithWatermanGotoh
+	logger     loggo.LoggerInterface
+	similarity matcher.Similarity
 }
 
 type args struct {
@@ -61,7 +63,49 @@ func TestGetMatchingTerms(t *testing.T) {
 			href:         "https://example.com/test",
 			anchorText:   "Example Anchor Text",
 			searchTerms:  []string{"example", "test"},
-			want:         []string{"test"},
+			want:         []string{"example", "test"},
+		},
+		{
+			name:        "Phrase matches consecutive words",
+			href:        "https://example.com/news/city-council-debates-drug-policy",
+			searchTerms: []string{"drug policy"},
+			want:        []string{"drug policy"},
+		},
+		{
+			name:        "Phrase does not match its words apart",
+			href:        "https://example.com/news/new-parking-policy-after-drug-bust",
+			searchTerms: []string{"drug policy"},
+			want:        []string{},
+		},
+		{
+			name:        "Phrase matches words with other endings",
+			href:        "https://example.com/news/drugs-policies-reviewed",
+			searchTerms: []string{"drug policy"},
+			want:        []string{"drug policy"},
+		},
+		{
+			name:        "Phrase ignores stopwords",
+			href:        "https://example.com/news/the-war-on-drugs-continues",
+			searchTerms: []string{"War on Drugs"},
+			want:        []string{"War on Drugs"},
+		},
+		{
+			name:        "Unquoted phrase matches similar words",
+			href:        "https://example.com/news/opioid-crises-deepens",
+			searchTerms: []string{"opioid crisis"},
+			want:        []string{"opioid crisis"},
+		},
+		{
+			name:        "Quoted phrase only matches its exact words",
+			href:        "https://example.com/news/opioid-crises-deepens",
+			searchTerms: []string{`"opioid crisis"`},
+			want:        []string{},
+		},
+		{
+			name:        "Quoted phrases keep their commas",
+			href:        "https://example.com/news/drug-policy-reform",
+			searchTerms: []string{`fire, "drug policy, reform"`},
+			want:        []string{"drug policy, reform"},
 		},
 	}
 
@@ -80,12 +124,245 @@ func TestGetMatchingTerms(t *testing.T) {
 	}
 }
 
+func TestGetMatchingTermsInText(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name        string
+		text        string
+		searchTerms []string
+		want        []string
+	}{
+		{
+			name:        "Term in body text",
+			text:        "Residents were told to leave their homes as the flooding spread across the valley.",
+			searchTerms: []string{"flood", "police"},
+			want:        []string{"flood"},
+		},
+		{
+			name:        "No term in body text",
+			text:        "The council approved the budget on Tuesday.",
+			searchTerms: []string{"flood"},
+			want:        []string{},
+		},
+		{
+			name:        "Text too short",
+			text:        "a",
+			searchTerms: []string{"flood"},
+			want:        []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := loggo.NewMockLoggerInterface(ctrl)
+			mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+			tm := NewTermMatcher(mockLogger, nil)
+			got := tm.GetMatchingTermsInText(tt.text, tt.searchTerms)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMatchingTermsInText() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetMatchingTermsWithSimilarity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name        string
+		metric      string
+		threshold   float64
+		href        string
+		searchTerms []string
+		want        []string
+	}{
+		{
+			name:        "Jaro-Winkler matches a misspelling",
+			metric:      matcher.MetricJaroWinkler,
+			href:        "https://example.com/news/fentanil-seized-at-border",
+			searchTerms: []string{"fentanyl"},
+			want:        []string{"fentanyl"},
+		},
+		{
+			name:        "Levenshtein matches a misspelling",
+			metric:      matcher.MetricLevenshtein,
+			href:        "https://example.com/news/fentanil-seized-at-border",
+			searchTerms: []string{"fentanyl"},
+			want:        []string{"fentanyl"},
+		},
+		{
+			name:        "Jaccard matches a misspelling",
+			metric:      matcher.MetricJaccard,
+			href:        "https://example.com/news/fentanil-seized-at-border",
+			searchTerms: []string{"fentanyl"},
+			want:        []string{"fentanyl"},
+		},
+		{
+			name:        "Threshold of 1 only matches exactly",
+			metric:      matcher.MetricJaroWinkler,
+			threshold:   1,
+			href:        "https://example.com/news/fentanil-seized-at-border",
+			searchTerms: []string{"fentanyl"},
+			want:        []string{},
+		},
+		{
+			name:        "Whole string metrics do not match a term within a word",
+			metric:      matcher.MetricLevenshtein,
+			href:        "https://example.com/news/firefighters-called-to-warehouse",
+			searchTerms: []string{"fire"},
+			want:        []string{},
+		},
+		{
+			name:        "Phrase matches with a whole string metric",
+			metric:      matcher.MetricJaroWinkler,
+			href:        "https://example.com/news/city-council-debates-drug-polcy",
+			searchTerms: []string{"drug policy"},
+			want:        []string{"drug policy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := loggo.NewMockLoggerInterface(ctrl)
+			mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+			similarity, err := matcher.NewSimilarity(tt.metric, tt.threshold)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			base := NewTermMatcher(mockLogger, nil)
+			tm := base.WithSimilarity(similarity)
+			got := tm.GetMatchingTerms(tt.href, "", tt.searchTerms)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMatchingTerms() = %v, want %v", got, tt.want)
+			}
+			if base.Similarity().Name != matcher.MetricSmithWatermanGotoh {
+				t.Errorf("WithSimilarity() changed the original to %v", base.Similarity().Name)
+			}
+		})
+	}
+}
+
+func TestGetMatchingTermsWithTopics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := loggo.NewMockLoggerInterface(ctrl)
+	mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+	topic := matcher.NewTopic("weather", func(content string) bool {
+		return strings.Contains(content, "storm")
+	})
+	base := NewTermMatcher(mockLogger, nil)
+	tm := base.WithMatchers([]matcher.Matcher{topic})
+
+	query, err := ParseQuery("flood -sports")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{
+			name: "Topic reported instead of the search terms",
+			got:  tm.GetMatchingTerms("https://example.com/news/storm-hits-coast", "", []string{"fire"}),
+			want: []string{"weather"},
+		},
+		{
+			name: "Topic next to a search term",
+			got:  tm.GetMatchingTerms("https://example.com/news/storm-floods-coast", "", []string{"flood"}),
+			want: []string{"weather", "flood"},
+		},
+		{
+			name: "Topic without a query match",
+			got:  tm.GetQueryMatchingTerms("https://example.com/news/storm-hits-coast", "", query),
+			want: []string{"weather"},
+		},
+		{
+			name: "Original has no topics",
+			got:  base.GetMatchingTerms("https://example.com/news/storm-hits-coast", "", []string{"fire"}),
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelevance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := loggo.NewMockLoggerInterface(ctrl)
+	mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+	tm := NewTermMatcher(mockLogger, nil)
+
+	title := tm.Relevance(map[string]string{models.MatchLocationTitle: "Flood warning issued"}, []string{"flood"})
+	body := tm.Relevance(map[string]string{models.MatchLocationBody: "Residents were warned of a flood"}, []string{"flood"})
+	twoTerms := tm.Relevance(map[string]string{models.MatchLocationBody: "Residents were warned of a flood after the fire"}, []string{"flood", "fire"})
+	repeated := tm.Relevance(map[string]string{models.MatchLocationBody: "Flood after flood after flood"}, []string{"flood"})
+	phrase := tm.Relevance(map[string]string{models.MatchLocationBody: "The council debated drug policy"}, []string{"drug policy"})
+	words := tm.Relevance(map[string]string{models.MatchLocationBody: "The council debated drug and policy"}, []string{"drug", "policy"})
+	bothPlaces := tm.Relevance(map[string]string{
+		models.MatchLocationURL:    "flood-warning-issued",
+		models.MatchLocationAnchor: "Flood warning",
+	}, []string{"flood"})
+	url := tm.Relevance(map[string]string{models.MatchLocationURL: "flood-warning-issued"}, []string{"flood"})
+
+	assert.Greater(t, title, body, "a term in the title is more relevant than in the body")
+	assert.Greater(t, twoTerms, body, "more distinct terms are more relevant")
+	assert.Greater(t, repeated, body, "a term that occurs more often is more relevant")
+	assert.Greater(t, phrase, tm.Relevance(map[string]string{models.MatchLocationBody: "The council debated drug policy"}, []string{"drug"}), "a phrase is more relevant than one of its words")
+	assert.Greater(t, words, 0.0)
+	assert.Greater(t, bothPlaces, url, "a term in several places is more relevant")
+	assert.Equal(t, 0.0, tm.Relevance(map[string]string{models.MatchLocationBody: "Nothing to see"}, nil))
+
+	for _, score := range []float64{title, body, twoTerms, repeated, phrase, bothPlaces} {
+		assert.LessOrEqual(t, score, 1.0)
+	}
+}
+
+func TestRelevanceOfTopics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := loggo.NewMockLoggerInterface(ctrl)
+	mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+	topic := matcher.NewTopic("weather", func(content string) bool {
+		return strings.Contains(content, "storm")
+	})
+	tm := NewTermMatcher(mockLogger, nil).WithMatchers([]matcher.Matcher{topic})
+
+	texts := map[string]string{models.MatchLocationURL: "storm-hits-coast"}
+	assert.Greater(t, tm.Relevance(texts, []string{"weather"}), 0.0)
+}
+
 func TestTermMatcher_compareAndAppendTerm(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	logger := loggo.NewMockLogger(ctrl)
-	swg := metrics.NewSmithWatermanGotoh()
+	similarity, err := matcher.NewSimilarity(matcher.MetricSmithWatermanGotoh, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	type args struct {
 		searchTerm string
@@ -101,8 +378,8 @@ func TestTermMatcher_compareAndAppendTerm(t *testing.T) {
 		{
 			name: "Test case 1: Exact match",
 			fields: fields{
-				logger: logger,
-				swg:    swg,
+				logger:     logger,
+				similarity: similarity,
 			},
 			args: args{
 				searchTerm: "hello",
@@ -113,8 +390,8 @@ func TestTermMatcher_compareAndAppendTerm(t *testing.T) {
 		{
 			name: "Test case 2: No match",
 			fields: fields{
-				logger: logger,
-				swg:    swg,
+				logger:     logger,
+				similarity: similarity,
 			},
 			args: args{
 				searchTerm: "goodbye",
@@ -130,8 +407,8 @@ func TestTermMatcher_compareAndAppendTerm(t *testing.T) {
 			mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
 
 			tm := &TermMatcher{
-				logger: mockLogger,
-				swg:    tt.fields.swg,
+				logger:     mockLogger,
+				similarity: tt.fields.similarity,
 			}
 			if got := tm.compareAndAppendTerm(tt.args.searchTerm, tt.args.content); got != tt.want {
 				t.Errorf("TermMatcher.compareAndAppendTerm() = %v, want %v", got, tt.want)
@@ -139,3 +416,290 @@ func TestTermMatcher_compareAndAppendTerm(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSearchTerms(t *testing.T) {
+	tests := []struct {
+		terms string
+		want  []string
+	}{
+		{terms: "fire, flood", want: []string{"fire", "flood"}},
+		{terms: "drug policy,fire", want: []string{"drug policy", "fire"}},
+		{terms: `"drug policy", fire`, want: []string{`"drug policy"`, "fire"}},
+		{terms: `"drugs, guns", fire`, want: []string{`"drugs, guns"`, "fire"}},
+		{terms: `fire,, "" ,`, want: []string{"fire"}},
+		{terms: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.terms, func(t *testing.T) {
+			if got := ParseSearchTerms(tt.terms); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSearchTerms() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    string
+		terms   []string
+		wantErr bool
+	}{
+		{query: "(opioid OR fentanyl) AND toronto -sports", want: "((opioid OR fentanyl) AND toronto AND -sports)", terms: []string{"opioid", "fentanyl", "toronto"}},
+		{query: "opioid fentanyl", want: "(opioid AND fentanyl)", terms: []string{"opioid", "fentanyl"}},
+		{query: "fire, flood OR +police", want: "(fire OR flood OR +police)", terms: []string{"fire", "flood", "police"}},
+		{query: `"drug policy" NOT (sports OR weather)`, want: `("drug policy" AND -(sports OR weather))`, terms: []string{`"drug policy"`}},
+		{query: "covid-19 toronto", want: "(covid-19 AND toronto)", terms: []string{"covid-19", "toronto"}},
+		{query: "NOT NOT fire", want: "--fire", terms: []string{"fire"}},
+		{query: "", wantErr: true},
+		{query: "(fire OR flood", wantErr: true},
+		{query: "fire)", wantErr: true},
+		{query: "fire AND", wantErr: true},
+		{query: "OR fire", wantErr: true},
+		{query: `"drug policy`, wantErr: true},
+		{query: "fire - flood", wantErr: true},
+		{query: "-sports", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := ParseQuery(tt.query)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidQuery) {
+					t.Fatalf("ParseQuery() error = %v, want ErrInvalidQuery", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseQuery() error = %v", err)
+			}
+			if got := query.root.String(); got != tt.want {
+				t.Errorf("ParseQuery() = %v, want %v", got, tt.want)
+			}
+			if got := query.Terms(); !reflect.DeepEqual(got, tt.terms) {
+				t.Errorf("Terms() = %v, want %v", got, tt.terms)
+			}
+		})
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		found []string
+		want  bool
+	}{
+		{query: "fire OR flood", found: []string{"fire"}, want: true},
+		{query: "fire OR +flood", found: []string{"fire"}, want: false},
+		{query: "fire OR +flood", found: []string{"flood"}, want: true},
+		{query: "fire, +flood, +police", found: []string{"fire", "flood"}, want: false},
+		{query: "fire, +flood, +police", found: []string{"flood", "police"}, want: true},
+		{query: "+fire flood", found: []string{"fire"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery() error = %v", err)
+			}
+
+			found := func(term string) bool {
+				for _, f := range tt.found {
+					if f == term {
+						return true
+					}
+				}
+				return false
+			}
+			if got := query.Match(found); got != tt.want {
+				t.Errorf("Match(%v) = %v, want %v", tt.found, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetQueryMatchingTerms(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const query = "(opioid OR fentanyl) AND toronto -sports"
+
+	tests := []struct {
+		name string
+		href string
+		want []string
+	}{
+		{
+			name: "All required terms",
+			href: "https://example.com/news/toronto-fentanyl-deaths-rise",
+			want: []string{"fentanyl", "toronto"},
+		},
+		{
+			name: "Both alternatives",
+			href: "https://example.com/news/opioid-and-fentanyl-crisis-in-toronto",
+			want: []string{"opioid", "fentanyl", "toronto"},
+		},
+		{
+			name: "Missing required term",
+			href: "https://example.com/news/fentanyl-deaths-rise",
+			want: []string{},
+		},
+		{
+			name: "Excluded term",
+			href: "https://example.com/news/toronto-sports-star-faces-fentanyl-charge",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := loggo.NewMockLoggerInterface(ctrl)
+			mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+			q, err := ParseQuery(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tm := NewTermMatcher(mockLogger, nil)
+			got := tm.GetQueryMatchingTerms(tt.href, "", q)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetQueryMatchingTerms() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := loggo.NewMockLoggerInterface(ctrl)
+	mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+	topic := matcher.NewTopic("weather", func(content string) bool {
+		return strings.Contains(content, "storm")
+	})
+	tm := NewTermMatcher(mockLogger, nil).WithMatchers([]matcher.Matcher{topic})
+
+	const href = "https://example.com/news/fentanil-seized-at-the-border"
+	searchTerms := []string{`fentanyl,border,"seized border",drug policy`}
+
+	e := tm.Explain(href, "Police seize drugs", searchTerms)
+
+	assert.Equal(t, "fentanil-seized-at-the-border", e.Slug.Text)
+	assert.Equal(t, "fentanil seized border", e.Slug.WithoutStopwords)
+	assert.Equal(t, "fentanil seiz border", e.Slug.Stemmed)
+	assert.Equal(t, "polic seiz drug", e.AnchorText.Stemmed)
+	assert.Equal(t, "fentanil seiz border polic seiz drug", e.Content)
+	assert.Equal(t, matcher.MetricSmithWatermanGotoh, e.Metric)
+	assert.Equal(t, 0.9, e.Threshold)
+	assert.Equal(t, []TopicExplanation{{Name: "weather"}}, e.Topics)
+
+	if assert.Len(t, e.Terms, 4) {
+		assert.False(t, e.Terms[0].ExactMatch)
+		assert.Less(t, e.Terms[0].Score, e.Threshold)
+		assert.True(t, e.Terms[1].ExactMatch)
+		assert.True(t, e.Terms[2].Quoted)
+		assert.Equal(t, "seiz border", e.Terms[2].Processed)
+		assert.True(t, e.Terms[2].Matched)
+		assert.False(t, e.Terms[3].Matched)
+	}
+
+	// The report agrees with GetMatchingTerms
+	var matched []string
+	for _, term := range e.Terms {
+		if term.Matched {
+			matched = append(matched, term.Term)
+		}
+	}
+	assert.Equal(t, tm.GetMatchingTerms(href, "Police seize drugs", searchTerms), matched)
+	assert.Equal(t, matched, e.MatchingTerms)
+	assert.True(t, e.Matched)
+}
+
+func TestExplainQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := loggo.NewMockLoggerInterface(ctrl)
+	mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+	q, err := ParseQuery("(opioid OR fentanyl) AND toronto -sports")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tm := NewTermMatcher(mockLogger, nil)
+
+	e := tm.ExplainQuery("https://example.com/news/toronto-sports-star-faces-fentanyl-charge", "", q)
+	assert.Equal(t, q.String(), e.Query)
+	assert.False(t, e.QueryMatched)
+	assert.False(t, e.Matched)
+	if assert.Len(t, e.Terms, 4) {
+		assert.Equal(t, "sports", e.Terms[3].Term)
+		assert.True(t, e.Terms[3].Excluded)
+		assert.True(t, e.Terms[3].Matched)
+	}
+
+	e = tm.ExplainQuery("https://example.com/news/toronto-fentanyl-deaths-rise", "", q)
+	assert.True(t, e.QueryMatched)
+	assert.Equal(t, []string{"fentanyl", "toronto"}, e.MatchingTerms)
+
+	e = tm.ExplainQuery("https://example.com/news/fire", "", q)
+	assert.True(t, e.TooShort)
+	assert.False(t, e.Matched)
+}
+
+func TestGetMatchingTermsInLanguage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := loggo.NewMockLoggerInterface(ctrl)
+	mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+	tm := NewTermMatcher(mockLogger, nil)
+
+	tests := []struct {
+		name     string
+		language string
+		href     string
+		term     string
+	}{
+		{"French plural", "fr", "https://www.lapresse.ca/actualites/les-hopitaux-debordes", "hôpital"},
+		{"French accents", "fr-CA", "https://www.lapresse.ca/actualites/saisie-record-d-opioides-a-montreal", "opioïde"},
+		{"Spanish plural", "es", "https://elpais.com/sociedad/detenidos-dos-policias-por-trafico", "policía"},
+		{"Spanish accents", "es", "https://elpais.com/sociedad/la-policia-desmantela-una-red-de-trafico-de-fentanilo", "tráfico"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Empty(t, tm.GetMatchingTerms(tt.href, "", []string{tt.term}), "in English")
+			assert.Equal(t, []string{tt.term}, tm.WithLanguage(tt.language).GetMatchingTerms(tt.href, "", []string{tt.term}))
+		})
+	}
+
+	assert.Equal(t, "en", tm.Language())
+	assert.Equal(t, "fr", tm.WithLanguage("fr-CA").Language())
+	assert.Equal(t, "en", tm.WithLanguage("de").Language())
+}
+
+func TestMatchingAndRelevanceAgree(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := loggo.NewMockLoggerInterface(ctrl)
+	mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+	tm := NewTermMatcher(mockLogger, nil)
+
+	// Stemming "abuse" twice gives a different word than stemming it once
+	text := "Police warn of drug abuse downtown"
+	terms := []string{`"drug abuse"`}
+	assert.Equal(t, []string{"drug abuse"}, tm.GetMatchingTermsInText(text, terms))
+	assert.Greater(t, tm.Relevance(map[string]string{models.MatchLocationBody: text}, []string{"drug abuse"}), 0.0)
+	assert.Empty(t, tm.GetMatchingTermsInText("Police warn of drug use downtown", terms))
+}
@@ -0,0 +1,125 @@
+package termmatcher
+
+import (
+	"strings"
+)
+
+// searchTerm is a search term as it is matched against processed content.
+type searchTerm struct {
+	text  string   // the term as given, without quotes, reported when it matches
+	words []string // the processed words of the term
+	exact bool     // quoted terms only match their exact words, in order
+}
+
+// ParseSearchTerms splits a comma separated list of search terms. Commas
+// inside double quotes do not split, and the quotes are kept so that the
+// phrase is matched exactly.
+func ParseSearchTerms(terms string) []string {
+	var result []string
+	var term strings.Builder
+	quoted := false
+
+	add := func() {
+		if t := strings.TrimSpace(term.String()); t != "" && t != `""` {
+			result = append(result, t)
+		}
+		term.Reset()
+	}
+
+	for _, r := range terms {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			term.WriteRune(r)
+		case r == ',' && !quoted:
+			add()
+		default:
+			term.WriteRune(r)
+		}
+	}
+	add()
+
+	return result
+}
+
+// parseSearchTerms parses the search terms for matching. Each entry may hold
+// several comma separated terms.
+func (tm *TermMatcher) parseSearchTerms(searchTerms []string) []searchTerm {
+	var result []searchTerm
+	for _, terms := range searchTerms {
+		for _, term := range ParseSearchTerms(terms) {
+			if parsed, ok := tm.newSearchTerm(term); ok {
+				result = append(result, parsed)
+			}
+		}
+	}
+	return result
+}
+
+// newSearchTerm processes a term the way content is processed, so that its
+// words can be compared with the words contentWords returns for the content.
+func (tm *TermMatcher) newSearchTerm(term string) (searchTerm, bool) {
+	text := strings.TrimSpace(term)
+	exact := false
+	if len(text) >= 2 && strings.HasPrefix(text, `"`) && strings.HasSuffix(text, `"`) {
+		text = strings.TrimSpace(text[1 : len(text)-1])
+		exact = true
+	}
+
+	processed := tm.processContent(text)
+	if processed == "" {
+		// The term is only stopwords, which content does not have
+		processed = tm.stemContent(tm.convertToLowercase(text))
+	}
+
+	words := tm.contentWords(processed)
+	return searchTerm{text: text, words: words, exact: exact}, len(words) > 0
+}
+
+// matches reports whether the term is in content, the words of processed
+// content. A single word matches as before, exactly or by similarity with
+// the content. A phrase matches a window of as many consecutive words of the
+// content, exactly or, unless it is quoted, by similarity.
+func (tm *TermMatcher) matches(term searchTerm, content []string) bool {
+	if len(term.words) == 1 && !term.exact {
+		return tm.compareAndAppendTerm(term.words[0], strings.Join(content, " "))
+	}
+
+	phrase := strings.Join(term.words, " ")
+	n := len(term.words)
+	for i := 0; i+n <= len(content); i++ {
+		window := content[i : i+n]
+		if equalWords(window, term.words) {
+			tm.logger.Debug("Exact matching phrase found: " + term.text)
+			return true
+		}
+		if term.exact {
+			continue
+		}
+
+		// Short windows would match any phrase they are part of when aligned
+		// locally, the other metrics penalise the difference in length
+		text := strings.Join(window, " ")
+		if tm.similarity.IsLocal() && len(text) < len(phrase) {
+			continue
+		}
+		if tm.similarity.Match(phrase, text) {
+			tm.logger.Debug("Matching phrase found: " + term.text)
+			return true
+		}
+	}
+
+	return false
+}
+
+func equalWords(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+package topic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jonesrussell/page-prowler/internal/language"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidDictionary is returned for dictionaries that cannot be used.
+var ErrInvalidDictionary = errors.New("invalid topic dictionary")
+
+// Dictionary defines a topic, such as "housing" or "crime", by the terms that
+// are about it. For example, in YAML:
+//
+//	name: mining
+//	language: en
+//	threshold: 0.6
+//	include:
+//	  - mining
+//	  - term: gold mining
+//	    weight: 2
+//	exclude:
+//	  - sports
+//
+// Content is about the topic when the weights of the include terms it
+// matches add up to at least MinScore, 1 by default, and it has none of the
+// exclude terms. Terms match when their similarity with the content is at
+// least Threshold, the default of the similarity metric if it is 0.
+type Dictionary struct {
+	Name      string   `json:"name" yaml:"name"`
+	Language  string   `json:"language,omitempty" yaml:"language,omitempty"`
+	Threshold float64  `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	MinScore  float64  `json:"min_score,omitempty" yaml:"min_score,omitempty"`
+	Include   []Term   `json:"include" yaml:"include"`
+	Exclude   []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+}
+
+// Term is an include term of a dictionary and its weight, 1 if it is not
+// given. It is written either as the term alone or as an object with term
+// and weight fields.
+type Term struct {
+	Text   string  `json:"term" yaml:"term"`
+	Weight float64 `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// UnmarshalYAML reads a term written as a string or as an object.
+func (t *Term) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&t.Text)
+	}
+	type plain Term
+	return node.Decode((*plain)(t))
+}
+
+// UnmarshalJSON reads a term written as a string or as an object.
+func (t *Term) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &t.Text); err == nil {
+		return nil
+	}
+	type plain Term
+	return json.Unmarshal(data, (*plain)(t))
+}
+
+// Parse reads a dictionary in the format of its extension, ".yaml", ".yml"
+// or ".json".
+func Parse(data []byte, ext string) (*Dictionary, error) {
+	var d Dictionary
+	var err error
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &d)
+	case ".json":
+		err = json.Unmarshal(data, &d)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidDictionary, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDictionary, err)
+	}
+
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// validate checks the dictionary and fills in its defaults.
+func (d *Dictionary) validate() error {
+	d.Name = strings.ToLower(strings.TrimSpace(d.Name))
+	if d.Name == "" {
+		return fmt.Errorf("%w: missing name", ErrInvalidDictionary)
+	}
+	if d.Language == "" {
+		d.Language = language.Default
+	}
+	lang, err := language.Parse(d.Language)
+	if err != nil {
+		return fmt.Errorf("%w %q: %v", ErrInvalidDictionary, d.Name, err)
+	}
+	d.Language = lang
+	if d.Threshold < 0 || d.Threshold > 1 {
+		return fmt.Errorf("%w %q: threshold must be between 0 and 1", ErrInvalidDictionary, d.Name)
+	}
+	if d.MinScore < 0 {
+		return fmt.Errorf("%w %q: min_score must not be negative", ErrInvalidDictionary, d.Name)
+	}
+	if d.MinScore == 0 {
+		d.MinScore = 1
+	}
+	if len(d.Include) == 0 {
+		return fmt.Errorf("%w %q: no include terms", ErrInvalidDictionary, d.Name)
+	}
+	for i, term := range d.Include {
+		if strings.TrimSpace(term.Text) == "" {
+			return fmt.Errorf("%w %q: empty include term", ErrInvalidDictionary, d.Name)
+		}
+		if term.Weight < 0 {
+			return fmt.Errorf("%w %q: weight of %q must not be negative", ErrInvalidDictionary, d.Name, term.Text)
+		}
+		if term.Weight == 0 {
+			d.Include[i].Weight = 1
+		}
+	}
+	return nil
+}
+
+// MustParse is like Parse but panics if the dictionary is invalid. It is for
+// the dictionaries built into the binary.
+func MustParse(data []byte, ext string) *Dictionary {
+	d, err := Parse(data, ext)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// LoadFile reads the dictionary in path.
+func LoadFile(path string) (*Dictionary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read topic dictionary: %v", err)
+	}
+
+	d, err := Parse(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return d, nil
+}
+
+// isDictionaryFile reports whether path has the extension of a dictionary.
+func isDictionaryFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// LoadDir reads the dictionaries of the YAML and JSON files in dir, sorted
+// by name. Two files defining the same topic are an error.
+func LoadDir(dir string) ([]*Dictionary, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read topic directory: %v", err)
+	}
+
+	var dictionaries []*Dictionary
+	files := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !isDictionaryFile(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		d, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := files[d.Name]; ok {
+			return nil, fmt.Errorf("%w: topic %q is defined in both %s and %s", ErrInvalidDictionary, d.Name, other, path)
+		}
+		files[d.Name] = path
+		dictionaries = append(dictionaries, d)
+	}
+
+	sort.Slice(dictionaries, func(i, j int) bool {
+		return dictionaries[i].Name < dictionaries[j].Name
+	})
+	return dictionaries, nil
+}
@@ -0,0 +1,93 @@
+package topic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	yamlData := `
+name: Housing
+threshold: 0.8
+min_score: 2
+include:
+  - rent
+  - term: affordable housing
+    weight: 2
+exclude:
+  - hotel
+`
+	jsonData := `{
+		"name": "Housing",
+		"threshold": 0.8,
+		"min_score": 2,
+		"include": ["rent", {"term": "affordable housing", "weight": 2}],
+		"exclude": ["hotel"]
+	}`
+
+	want := &Dictionary{
+		Name:      "housing",
+		Language:  "en",
+		Threshold: 0.8,
+		MinScore:  2,
+		Include:   []Term{{Text: "rent", Weight: 1}, {Text: "affordable housing", Weight: 2}},
+		Exclude:   []string{"hotel"},
+	}
+
+	for ext, data := range map[string]string{".yaml": yamlData, ".yml": yamlData, ".json": jsonData} {
+		t.Run(ext, func(t *testing.T) {
+			d, err := Parse([]byte(data), ext)
+			require.NoError(t, err)
+			assert.Equal(t, want, d)
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		ext  string
+	}{
+		{name: "unsupported format", data: "name = \"crime\"", ext: ".toml"},
+		{name: "malformed", data: "name: [crime", ext: ".yaml"},
+		{name: "missing name", data: "include: [theft]", ext: ".yaml"},
+		{name: "no include terms", data: "name: crime", ext: ".yaml"},
+		{name: "threshold above 1", data: "name: crime\nthreshold: 2\ninclude: [theft]", ext: ".yaml"},
+		{name: "negative weight", data: "name: crime\ninclude:\n  - term: theft\n    weight: -1", ext: ".yaml"},
+		{name: "unsupported language", data: "name: crime\nlanguage: de\ninclude: [diebstahl]", ext: ".yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), tt.ext)
+			assert.ErrorIs(t, err, ErrInvalidDictionary)
+		})
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "crime.yaml", "name: crime\ninclude: [theft, assault]")
+	writeFile(t, dir, "housing.json", `{"name": "housing", "include": ["rent"]}`)
+	writeFile(t, dir, "README.md", "Topic dictionaries")
+
+	dictionaries, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, dictionaries, 2)
+	assert.Equal(t, "crime", dictionaries[0].Name)
+	assert.Equal(t, "housing", dictionaries[1].Name)
+
+	writeFile(t, dir, "crime2.yml", "name: crime\ninclude: [robbery]")
+	_, err = LoadDir(dir)
+	assert.ErrorIs(t, err, ErrInvalidDictionary)
+}
+
+func writeFile(t *testing.T, dir, name, data string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644))
+}
@@ -0,0 +1,109 @@
+package topic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/jonesrussell/loggo"
+	"github.com/jonesrussell/page-prowler/internal/matcher"
+)
+
+// reloadDelay is how long Watch waits for changes to settle before it
+// reloads, so that an editor saving a file in several steps reloads once.
+const reloadDelay = 100 * time.Millisecond
+
+// Loader registers the dictionaries of a directory with a registry, and
+// reloads them when the files change. Crawls that have started keep the
+// matchers they were created with.
+type Loader struct {
+	dir      string
+	registry *matcher.Registry
+	logger   loggo.LoggerInterface
+
+	mu    sync.Mutex
+	names map[string]bool // the topics registered from the directory
+}
+
+// NewLoader creates a Loader for the dictionaries in dir.
+func NewLoader(dir string, registry *matcher.Registry, logger loggo.LoggerInterface) *Loader {
+	return &Loader{
+		dir:      dir,
+		registry: registry,
+		logger:   logger,
+		names:    make(map[string]bool),
+	}
+}
+
+// Load registers the dictionaries of the directory, replacing the topics it
+// registered before and unregistering those whose file is gone. If a
+// dictionary is invalid nothing changes.
+func (l *Loader) Load() error {
+	dictionaries, err := LoadDir(l.dir)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	names := make(map[string]bool)
+	for _, d := range dictionaries {
+		Register(l.registry, d)
+		names[d.Name] = true
+	}
+	for name := range l.names {
+		if !names[name] {
+			l.registry.Unregister(name)
+		}
+	}
+	l.names = names
+
+	return nil
+}
+
+// Watch reloads the dictionaries whenever a file of the directory changes,
+// until ctx is done. Errors reloading are logged, keeping the topics as they
+// were.
+func (l *Loader) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch topic directory: %v", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(l.dir); err != nil {
+		return fmt.Errorf("failed to watch topic directory: %v", err)
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if isDictionaryFile(event.Name) {
+				timer.Reset(reloadDelay)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			l.logger.Error("Error watching topic directory", err)
+		case <-timer.C:
+			if err := l.Load(); err != nil {
+				l.logger.Error("Error reloading topic dictionaries", err)
+				continue
+			}
+			l.logger.Info(fmt.Sprintf("Reloaded topic dictionaries from %s", l.dir))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package topic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonesrussell/loggo"
+	"github.com/jonesrussell/page-prowler/internal/matcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	logger := loggo.NewMockLoggerInterface(ctrl)
+	logger.EXPECT().Info(gomock.Any()).AnyTimes()
+	logger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "crime.yaml", "name: crime\ninclude: [theft]")
+
+	registry := matcher.NewRegistry()
+	registry.Register("builtin", Factory(MustParse([]byte("name: builtin\ninclude: [fire]"), ".yaml")))
+
+	loader := NewLoader(dir, registry, logger)
+	require.NoError(t, loader.Load())
+	assert.Equal(t, []string{"builtin", "crime"}, registry.Names())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- loader.Watch(ctx) }()
+
+	// Give the watcher time to start before changing the directory
+	time.Sleep(50 * time.Millisecond)
+
+	writeFile(t, dir, "housing.yaml", "name: housing\ninclude: [rent]")
+	require.NoError(t, os.Remove(filepath.Join(dir, "crime.yaml")))
+
+	assert.Eventually(t, func() bool {
+		names := registry.Names()
+		return len(names) == 2 && names[0] == "builtin" && names[1] == "housing"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// An invalid dictionary keeps the topics as they were
+	writeFile(t, dir, "broken.yaml", "name: broken")
+	time.Sleep(3 * reloadDelay)
+	assert.Equal(t, []string{"builtin", "housing"}, registry.Names())
+
+	cancel()
+	assert.NoError(t, <-done)
+}
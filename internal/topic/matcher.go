@@ -0,0 +1,122 @@
+package topic
+
+import (
+	"strings"
+
+	"github.com/jonesrussell/page-prowler/internal/matcher"
+)
+
+// Matcher matches content about the topic of a dictionary.
+type Matcher struct {
+	*matcher.BaseMatcher
+	dictionary *Dictionary
+	similarity matcher.Similarity
+	include    []Term   // the processed include terms
+	exclude    []string // the processed exclude terms
+}
+
+var _ matcher.NamedMatcher = &Matcher{}
+
+// NewMatcher creates a Matcher for the topic of d that compares terms with
+// similarity. A zero similarity uses Smith-Waterman-Gotoh, and a zero
+// threshold the threshold of the dictionary.
+func NewMatcher(d *Dictionary, similarity matcher.Similarity) *Matcher {
+	if similarity.Metric == nil {
+		similarity, _ = matcher.NewSimilarity(matcher.MetricSmithWatermanGotoh, d.Threshold)
+	} else if similarity.Threshold == 0 {
+		similarity.Threshold = d.Threshold
+	}
+
+	m := &Matcher{
+		BaseMatcher: matcher.NewBaseMatcherWithMetric(similarity.Metric),
+		dictionary:  d,
+		similarity:  similarity,
+	}
+
+	for _, term := range d.Include {
+		if text := m.process(term.Text); text != "" {
+			m.include = append(m.include, Term{Text: text, Weight: term.Weight})
+		}
+	}
+	for _, term := range d.Exclude {
+		if text := m.process(term); text != "" {
+			m.exclude = append(m.exclude, text)
+		}
+	}
+
+	return m
+}
+
+// Factory returns a matcher.Factory for the topic of d, to register it with.
+func Factory(d *Dictionary) matcher.Factory {
+	return func(similarity matcher.Similarity) matcher.Matcher {
+		return NewMatcher(d, similarity)
+	}
+}
+
+// Register registers the topic of d with registry under its name.
+func Register(registry *matcher.Registry, d *Dictionary) {
+	registry.Register(d.Name, Factory(d))
+}
+
+// process processes text the way the content is processed, in the language
+// of the dictionary.
+func (m *Matcher) process(text string) string {
+	return m.ProcessContentIn(text, m.dictionary.Language)
+}
+
+// Name returns the name of the topic.
+func (m *Matcher) Name() string {
+	return m.dictionary.Name
+}
+
+// Score returns the sum of the weights of the include terms found in
+// content, or 0 if it has an exclude term.
+func (m *Matcher) Score(content string) float64 {
+	content = m.process(content)
+	if content == "" {
+		return 0
+	}
+
+	for _, term := range m.exclude {
+		if strings.Contains(content, term) {
+			return 0
+		}
+	}
+
+	score := 0.0
+	for _, term := range m.include {
+		if m.contains(content, term.Text) {
+			score += term.Weight
+		}
+	}
+	return score
+}
+
+// contains reports whether processed content has term. Smith-Waterman-Gotoh
+// finds the term within the content, the other metrics compare it with each
+// run of as many words.
+func (m *Matcher) contains(content string, term string) bool {
+	if m.similarity.IsLocal() {
+		return m.similarity.Match(term, content)
+	}
+
+	words := strings.Fields(content)
+	n := len(strings.Fields(term))
+	for i := 0; i+n <= len(words); i++ {
+		if m.similarity.Match(term, strings.Join(words[i:i+n], " ")) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchText reports whether content is about the topic.
+func (m *Matcher) MatchText(content string) bool {
+	return m.Score(content) >= m.dictionary.MinScore
+}
+
+// Match implements matcher.Matcher. The pattern is ignored.
+func (m *Matcher) Match(content string, _ string) (bool, error) {
+	return m.MatchText(content), nil
+}
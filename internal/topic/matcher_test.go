@@ -0,0 +1,60 @@
+package topic
+
+import (
+	"testing"
+
+	"github.com/jonesrussell/page-prowler/internal/matcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher(t *testing.T) {
+	d := MustParse([]byte(`
+name: housing
+min_score: 2
+include:
+  - rent
+  - eviction
+  - term: affordable housing
+    weight: 2
+exclude:
+  - hotel
+`), ".yaml")
+
+	tests := []struct {
+		name      string
+		content   string
+		wantScore float64
+		want      bool
+	}{
+		{name: "one term is not enough", content: "Rent rises again", wantScore: 1, want: false},
+		{name: "two terms", content: "Evictions follow rent increases", wantScore: 2, want: true},
+		{name: "weighted term", content: "City plans affordable housing", wantScore: 2, want: true},
+		{name: "excluded", content: "Hotel rent and evictions", wantScore: 0, want: false},
+		{name: "no terms", content: "Council approves budget", wantScore: 0, want: false},
+	}
+
+	m := NewMatcher(d, matcher.Similarity{})
+	assert.Equal(t, "housing", m.Name())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantScore, m.Score(tt.content))
+			matched, err := m.Match(tt.content, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, matched)
+		})
+	}
+}
+
+func TestMatcherWithSimilarity(t *testing.T) {
+	d := MustParse([]byte(`{"name": "drugs", "include": ["fentanyl"]}`), ".json")
+
+	swg := NewMatcher(d, matcher.Similarity{})
+	assert.False(t, swg.MatchText("fentanil seized at border"))
+
+	similarity, err := matcher.NewSimilarity(matcher.MetricJaroWinkler, 0)
+	require.NoError(t, err)
+	jw := NewMatcher(d, similarity)
+	assert.True(t, jw.MatchText("fentanil seized at border"))
+}
@@ -3,13 +3,14 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/hibiken/asynq"
 	"github.com/jonesrussell/loggo"
 	"github.com/jonesrussell/page-prowler/crawler"
 	"github.com/jonesrussell/page-prowler/internal/tasks"
+	"github.com/jonesrussell/page-prowler/internal/termmatcher"
 )
 
 type AsynqLoggerWrapper struct {
@@ -36,41 +37,49 @@ func (l *AsynqLoggerWrapper) Fatal(args ...interface{}) {
 	l.logger.Debug(fmt.Sprint(args...))
 }
 
-func handleCrawlTask(task *asynq.Task, cm crawler.CrawlManagerInterface, debug bool) error {
+func handleCrawlTask(ctx context.Context, task *asynq.Task, cm crawler.CrawlManagerInterface, debug bool) error {
 	var payload tasks.CrawlTaskPayload
 	err := json.Unmarshal(task.Payload(), &payload)
 	if err != nil {
-		return err
+		// A malformed payload never succeeds, so don't retry it
+		return fmt.Errorf("failed to decode payload: %v: %w", err, asynq.SkipRetry)
 	}
 
-	searchTermsSlice := strings.Split(payload.SearchTerms, ",")
-
-	options := crawler.CrawlOptions{
-		StartURL:    payload.URL,
-		MaxDepth:    payload.MaxDepth,
-		SearchTerms: searchTermsSlice,
-		Debug:       debug,
+	options, err := payload.CrawlOptions()
+	if err != nil {
+		return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+	}
+	options.Debug = options.Debug || debug
+
+	// Tasks may run concurrently, so pass the options to this crawl only.
+	// ctx is cancelled when the task times out or is cancelled, which stops
+	// the crawl. Errors from the crawl itself are usually transient (network,
+	// storage), so Asynq retries them.
+	err = cm.CrawlWithOptions(ctx, &options)
+	if errors.Is(err, crawler.ErrNoSearchTerms) || errors.Is(err, termmatcher.ErrInvalidQuery) {
+		// Nothing to match until a query is saved for the site
+		return fmt.Errorf("crawl of %s for site %s failed: %v: %w", options.StartURL, options.CrawlSiteID, err, asynq.SkipRetry)
 	}
-
-	err = cm.SetOptions(&options)
 	if err != nil {
-		return err
+		return fmt.Errorf("crawl of %s for site %s failed: %w", options.StartURL, options.CrawlSiteID, err)
 	}
 
-	err = cm.Crawl()
-	return err
+	return nil
 }
 
-func StartWorker(concurrency int, manager crawler.CrawlManagerInterface, debug bool) {
-	dbManager := manager.GetDBManager()
+// NewServeMux returns a mux that handles crawl tasks with manager.
+func NewServeMux(manager crawler.CrawlManagerInterface, debug bool) *asynq.ServeMux {
+	mux := asynq.NewServeMux()
+	mux.HandleFunc(tasks.CrawlTaskType, func(ctx context.Context, task *asynq.Task) error {
+		return handleCrawlTask(ctx, task, manager, debug) // Pass the manager to the handleCrawlTask function
+	})
+	return mux
+}
 
+func StartWorker(concurrency int, manager crawler.CrawlManagerInterface, redisConnOpt asynq.RedisConnOpt, debug bool) {
 	// Initialize a new Asynq server with the default settings.
 	srv := asynq.NewServer(
-		asynq.RedisClientOpt{
-			Addr:     dbManager.RedisOptions().Addr,
-			Password: dbManager.RedisOptions().Password,
-			DB:       dbManager.RedisOptions().DB,
-		},
+		redisConnOpt,
 		asynq.Config{
 			Concurrency: concurrency,
 			Logger:      &AsynqLoggerWrapper{logger: manager.GetLogger()}, // Use the Logger from CrawlManager
@@ -78,10 +87,7 @@ func StartWorker(concurrency int, manager crawler.CrawlManagerInterface, debug b
 	)
 
 	// mux maps a task type to a handler
-	mux := asynq.NewServeMux()
-	mux.HandleFunc(tasks.CrawlTaskType, func(_ context.Context, task *asynq.Task) error {
-		return handleCrawlTask(task, manager, debug) // Pass the manager to the handleCrawlTask function
-	})
+	mux := NewServeMux(manager, debug)
 
 	// Run the server with the handler mux.
 	if err := srv.Run(mux); err != nil {
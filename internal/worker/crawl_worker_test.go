@@ -0,0 +1,225 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/hibiken/asynq"
+	"github.com/jonesrussell/loggo"
+	"github.com/jonesrussell/page-prowler/crawler"
+	"github.com/jonesrussell/page-prowler/dbmanager"
+	"github.com/jonesrussell/page-prowler/internal/prowlredis"
+	"github.com/jonesrussell/page-prowler/internal/tasks"
+	"github.com/jonesrussell/page-prowler/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCrawlManager records the options of every crawl and returns err.
+type fakeCrawlManager struct {
+	crawler.CrawlManagerInterface
+	options []crawler.CrawlOptions
+	err     error
+}
+
+func (m *fakeCrawlManager) CrawlWithOptions(_ context.Context, options *crawler.CrawlOptions) error {
+	m.options = append(m.options, *options)
+	return m.err
+}
+
+func newMockLogger(t *testing.T) *loggo.MockLoggerInterface {
+	t.Helper()
+
+	logger := loggo.NewMockLoggerInterface(gomock.NewController(t))
+	logger.EXPECT().Debug(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+	return logger
+}
+
+func newTask(t *testing.T, payload interface{}) *asynq.Task {
+	t.Helper()
+
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return asynq.NewTask(tasks.CrawlTaskType, data)
+}
+
+func TestHandleCrawlTaskCarriesPayload(t *testing.T) {
+	cm := &fakeCrawlManager{}
+	task := newTask(t, tasks.CrawlTaskPayload{
+		URL:                  "https://www.example.com",
+		SearchTerms:          "fire, flood",
+		CrawlSiteID:          "site-a",
+		MaxDepth:             2,
+		DelayBetweenRequests: "1s",
+		MatchContent:         true,
+		SimilarityMetric:     "jaro-winkler",
+		SimilarityThreshold:  0.85,
+		Matchers:             []string{"drug", "mining"},
+		Language:             "fr",
+		DiscoverFeeds:        true,
+		Feeds:                []string{"https://www.example.com/sitemap-news.xml"},
+		StartURLs:            []string{"https://news.example.com"},
+		AllowedDomains:       []string{"*.example.com"},
+		Include:              []string{"/news/"},
+		Exclude:              []string{"/video/", "?page="},
+		Incremental:          true,
+	})
+
+	require.NoError(t, handleCrawlTask(context.Background(), task, cm, false))
+	require.Len(t, cm.options, 1)
+
+	options := cm.options[0]
+	assert.Equal(t, "site-a", options.CrawlSiteID)
+	assert.Equal(t, "https://www.example.com", options.StartURL)
+	assert.Equal(t, 2, options.MaxDepth)
+	assert.Equal(t, []string{"fire", "flood"}, options.SearchTerms)
+	assert.Equal(t, time.Second, options.DelayBetweenRequests)
+	assert.True(t, options.MatchContent)
+	assert.Equal(t, "jaro-winkler", options.SimilarityMetric)
+	assert.Equal(t, 0.85, options.SimilarityThreshold)
+	assert.Equal(t, []string{"drug", "mining"}, options.Matchers)
+	assert.Equal(t, "fr", options.Language)
+	assert.True(t, options.DiscoverFeeds)
+	assert.Equal(t, []string{"https://www.example.com/sitemap-news.xml"}, options.Feeds)
+	assert.Equal(t, []string{"https://www.example.com", "https://news.example.com"}, options.Seeds())
+	assert.Equal(t, []string{"*.example.com"}, options.AllowedDomains)
+	assert.Equal(t, []string{"/news/"}, options.Include)
+	assert.Equal(t, []string{"/video/", "?page="}, options.Exclude)
+	assert.True(t, options.Incremental)
+}
+
+func TestHandleCrawlTaskRateLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		delay     string
+		wantDelay time.Duration
+	}{
+		{name: "default delay", delay: "", wantDelay: crawler.DefaultDelay},
+		{name: "no delay", delay: "0s", wantDelay: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &fakeCrawlManager{}
+			task := newTask(t, tasks.CrawlTaskPayload{
+				URL:                  "https://www.example.com",
+				SearchTerms:          "fire",
+				CrawlSiteID:          "site-a",
+				DelayBetweenRequests: tt.delay,
+			})
+
+			require.NoError(t, handleCrawlTask(context.Background(), task, cm, false))
+			require.Len(t, cm.options, 1)
+			assert.Equal(t, tt.wantDelay, cm.options[0].DelayBetweenRequests)
+			assert.Equal(t, crawler.DefaultParallelism, cm.options[0].MaxConcurrentRequests)
+		})
+	}
+}
+
+func TestHandleCrawlTaskRetryClassification(t *testing.T) {
+	valid := tasks.CrawlTaskPayload{URL: "https://www.example.com", SearchTerms: "fire", CrawlSiteID: "site-a"}
+
+	tests := []struct {
+		name      string
+		task      *asynq.Task
+		crawlErr  error
+		skipRetry bool
+	}{
+		{name: "malformed payload", task: asynq.NewTask(tasks.CrawlTaskType, []byte("{")), skipRetry: true},
+		{name: "missing site id", task: newTask(t, tasks.CrawlTaskPayload{URL: "https://www.example.com", SearchTerms: "fire"}), skipRetry: true},
+		{name: "invalid url", task: newTask(t, tasks.CrawlTaskPayload{URL: "not a url", SearchTerms: "fire", CrawlSiteID: "site-a"}), skipRetry: true},
+		{name: "invalid query", task: newTask(t, tasks.CrawlTaskPayload{URL: "https://www.example.com", Query: "(fire", CrawlSiteID: "site-a"}), skipRetry: true},
+		{name: "unknown similarity metric", task: newTask(t, tasks.CrawlTaskPayload{URL: "https://www.example.com", SearchTerms: "fire", CrawlSiteID: "site-a", SimilarityMetric: "soundex"}), skipRetry: true},
+		{name: "unknown matcher", task: newTask(t, tasks.CrawlTaskPayload{URL: "https://www.example.com", SearchTerms: "fire", CrawlSiteID: "site-a", Matchers: []string{"sports"}}), skipRetry: true},
+		{name: "unsupported language", task: newTask(t, tasks.CrawlTaskPayload{URL: "https://www.example.com", SearchTerms: "fire", CrawlSiteID: "site-a", Language: "de"}), skipRetry: true},
+		{name: "invalid allowed domain", task: newTask(t, tasks.CrawlTaskPayload{URL: "https://www.example.com", SearchTerms: "fire", CrawlSiteID: "site-a", AllowedDomains: []string{"https://example.com"}}), skipRetry: true},
+		{name: "invalid exclude rule", task: newTask(t, tasks.CrawlTaskPayload{URL: "https://www.example.com", SearchTerms: "fire", CrawlSiteID: "site-a", Exclude: []string{"re:(video"}}), skipRetry: true},
+		{name: "relative feed", task: newTask(t, tasks.CrawlTaskPayload{URL: "https://www.example.com", SearchTerms: "fire", CrawlSiteID: "site-a", Feeds: []string{"/feed.xml"}}), skipRetry: true},
+		{name: "no search terms", task: newTask(t, tasks.CrawlTaskPayload{URL: "https://www.example.com", CrawlSiteID: "site-a"}), crawlErr: crawler.ErrNoSearchTerms, skipRetry: true},
+		{name: "crawl failure", task: newTask(t, valid), crawlErr: errors.New("connection refused")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := handleCrawlTask(context.Background(), tt.task, &fakeCrawlManager{err: tt.crawlErr}, false)
+			require.Error(t, err)
+			assert.Equal(t, tt.skipRetry, errors.Is(err, asynq.SkipRetry))
+		})
+	}
+}
+
+func TestCrawlTaskEndToEnd(t *testing.T) {
+	site := http.NewServeMux()
+	site.HandleFunc("/robots.txt", http.NotFound)
+	site.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path != "/" {
+			fmt.Fprint(w, "<html><body><p>article</p></body></html>")
+			return
+		}
+		fmt.Fprint(w, `<html><body>
+			<a href="/news/flood-warning-downtown">Flood warning</a>
+			<a href="/news/weather">Weather</a>
+		</body></html>`)
+	})
+	server := httptest.NewServer(site)
+	defer server.Close()
+
+	mr := miniredis.RunT(t)
+	logger := newMockLogger(t)
+
+	redisClient, err := prowlredis.NewClient(context.Background(), &prowlredis.Options{Addr: mr.Addr()})
+	require.NoError(t, err)
+	dbManager := dbmanager.NewRedisManager(redisClient, logger)
+	manager := crawler.NewCrawlManager(logger, dbManager, nil, &crawler.CrawlOptions{})
+
+	redisConnOpt := asynq.RedisClientOpt{Addr: mr.Addr()}
+	srv := asynq.NewServer(redisConnOpt, asynq.Config{
+		Concurrency: 1,
+		Logger:      &AsynqLoggerWrapper{logger: logger},
+	})
+	require.NoError(t, srv.Start(NewServeMux(manager, false)))
+	defer srv.Shutdown()
+
+	client := asynq.NewClient(redisConnOpt)
+	defer client.Close()
+
+	_, err = tasks.EnqueueCrawlTask(client, &tasks.CrawlTaskPayload{
+		URL:                  server.URL,
+		SearchTerms:          "flood",
+		CrawlSiteID:          "fixture",
+		MaxDepth:             2,
+		DelayBetweenRequests: "1ms",
+	})
+	require.NoError(t, err)
+
+	var run models.CrawlRun
+	require.Eventually(t, func() bool {
+		runs, err := dbManager.ListCrawlRuns(context.Background(), "fixture")
+		if err != nil || len(runs) == 0 {
+			return false
+		}
+		run = runs[0]
+		return run.Status != models.CrawlRunStatusRunning
+	}, 10*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, models.CrawlRunStatusCompleted, run.Status)
+	assert.Equal(t, 3, run.TotalPages)
+	assert.Equal(t, 1, run.MatchedLinks)
+
+	results, err := dbManager.GetResults(context.Background(), "fixture")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, server.URL+"/news/flood-warning-downtown", results[0].URL)
+	assert.Equal(t, []string{"flood"}, results[0].MatchingTerms)
+}
@@ -2,25 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"log/slog"
 	"os"
-	"regexp"
 
 	"github.com/jonesrussell/loggo"
 
 	"github.com/gocolly/colly"
 	"github.com/gocolly/colly/debug"
-	"github.com/gocolly/redisstorage"
 	"github.com/jonesrussell/page-prowler/cmd"
 	"github.com/jonesrussell/page-prowler/crawler"
 	"github.com/jonesrussell/page-prowler/dbmanager"
 	"github.com/jonesrussell/page-prowler/internal/prowlredis"
+	"github.com/jonesrussell/page-prowler/internal/topic"
 	"github.com/jonesrussell/page-prowler/news"
 	"github.com/spf13/viper"
 )
 
+// InitializeManager creates the crawl manager. cfg is nil unless the Redis
+// backend is used.
 func InitializeManager(
 	dbManager dbmanager.DatabaseManagerInterface, // Add dbManager as a parameter
 	appLogger loggo.LoggerInterface,
@@ -35,48 +38,88 @@ func InitializeManager(
 		Output: file,
 	}
 
-	var (
-		// URLFilters Define your allowed URLs
-		URLFilters []*regexp.Regexp
-	)
-
-	// Create a new Colly collector
-	collector := colly.NewCollector(
-		colly.Debugger(debugger),
-		colly.MaxDepth(1),
-		colly.URLFilters(URLFilters...),
-	)
-
-	collectorWrapper := crawler.NewCollectorWrapper(collector, appLogger, URLFilters)
-
-	// Create the Redis storage
-	storage := &redisstorage.Storage{
-		Address:  cfg.Addr,
-		Password: cfg.Password,
-		DB:       1, // TODO: redis storage db
-		Prefix:   "prowl",
-	}
+	// Every crawl gets a fresh collector and storage. With Redis the storage
+	// keys are under a prefix of their own in REDIS_STORAGE_DB, otherwise it
+	// is kept in memory.
+	// The URLs each crawl follows are set by its Include and Exclude rules.
+	collectorFactory := func(runID string, _ *crawler.CrawlOptions) (*crawler.CollectorWrapper, crawler.CrawlStorage, error) {
+		collector := colly.NewCollector(
+			colly.Debugger(debugger),
+			colly.MaxDepth(1),
+		)
 
-	// Set the storage for the collector
-	err = collector.SetStorage(storage)
-	if err != nil {
-		return nil, fmt.Errorf("failed to set storage: %v", err)
-	}
+		collectorWrapper := crawler.NewCollectorWrapper(collector, appLogger, nil)
 
-	// delete previous data from storage
-	if err := storage.Clear(); err != nil {
-		log.Fatal(err)
+		if cfg == nil {
+			return collectorWrapper, crawler.NewInMemoryStorage(), nil
+		}
+
+		storage := crawler.NewRedisStorage(cfg.Addr, cfg.Password, viper.GetInt("REDIS_STORAGE_DB"), "prowl:"+runID)
+
+		return collectorWrapper, storage, nil
 	}
 
 	return crawler.NewCrawlManager(
 		appLogger,
 		dbManager,
-		collectorWrapper,
+		collectorFactory,
 		&crawler.CrawlOptions{},
-		storage,
 	), nil
 }
 
+// newDBManager creates the storage backend chosen by DB_BACKEND. Without
+// DB_BACKEND, Redis is used if REDIS_HOST is set and bolt otherwise. The Redis
+// options are nil unless the Redis backend is used.
+func newDBManager(ctx context.Context, logger loggo.LoggerInterface) (dbmanager.DatabaseManagerInterface, *prowlredis.Options, error) {
+	redisHost := viper.GetString("REDIS_HOST")
+	redisPort := viper.GetString("REDIS_PORT")
+	redisAuth := viper.GetString("REDIS_AUTH")
+
+	backend := viper.GetString("DB_BACKEND")
+	if backend == "" {
+		backend = dbmanager.BackendBolt
+		if redisHost != "" {
+			backend = dbmanager.BackendRedis
+		}
+	}
+
+	switch backend {
+	case dbmanager.BackendRedis:
+		if redisHost == "" || redisPort == "" {
+			return nil, nil, errors.New("REDIS_HOST or REDIS_PORT is not set but is required")
+		}
+
+		cfg := &prowlredis.Options{
+			Addr:     fmt.Sprintf("%s:%s", redisHost, redisPort),
+			Password: redisAuth,
+			DB:       0, // TODO: redisDB
+		}
+
+		redisClient, err := prowlredis.NewClient(ctx, cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize Redis client: %v", err)
+		}
+
+		return dbmanager.NewRedisManager(redisClient, logger), cfg, nil
+
+	case dbmanager.BackendBolt:
+		path := viper.GetString("BOLT_PATH")
+		if path == "" {
+			path = "page-prowler.db"
+		}
+
+		dbManager, err := dbmanager.NewBoltManager(path, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return dbManager, nil, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown DB_BACKEND %q, expected %q or %q", backend, dbmanager.BackendRedis, dbmanager.BackendBolt)
+	}
+}
+
 func main() {
 	// Create a new logger instance with debug level
 	logger, err := loggo.NewLogger("./loggo.log", slog.LevelDebug)
@@ -87,37 +130,24 @@ func main() {
 
 	// Initialize Viper
 	viper.AutomaticEnv() // Read environment variables
+	viper.SetDefault("REDIS_STORAGE_DB", 1)
 	viper.SetConfigFile(".env")
 	err = viper.ReadInConfig()
-	if err != nil {
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		fmt.Println("Could not read config file", err) // Use fmt.Println for simplicity here since logger isn't ready yet
 		return
 	}
 
-	redisHost := viper.GetString("REDIS_HOST")
-	redisPort := viper.GetString("REDIS_PORT")
-	redisAuth := viper.GetString("REDIS_AUTH")
-
-	if redisHost == "" || redisPort == "" {
-		fmt.Println("REDIS_HOST or REDIS_PORT is not set but is required")
-		return
-	}
-
-	cfg := &prowlredis.Options{
-		Addr:     fmt.Sprintf("%s:%s", redisHost, redisPort),
-		Password: redisAuth,
-		DB:       0, // TODO: redisDB
-	}
-
-	ctx := context.Background()
-
-	redisClient, err := prowlredis.NewClient(ctx, cfg)
+	dbManager, cfg, err := newDBManager(context.Background(), logger)
 	if err != nil {
-		fmt.Println("Failed to initialize Redis client:", err)
+		fmt.Println("Failed to initialize storage:", err)
 		return
 	}
-
-	dbManager := dbmanager.NewRedisManager(redisClient, logger) // Create a new DatabaseManager instance
+	defer func() {
+		if err := dbManager.Close(); err != nil {
+			fmt.Println("Failed to close storage:", err)
+		}
+	}()
 
 	// Create the news service
 	newsService := news.NewMockService()
@@ -129,6 +159,23 @@ func main() {
 		return
 	}
 
+	// Load the topic dictionaries of TOPICS_DIR, and reload them as they change
+	if dir := viper.GetString("TOPICS_DIR"); dir != "" {
+		loader := topic.NewLoader(dir, manager.Matchers, logger)
+		if err := loader.Load(); err != nil {
+			fmt.Println("Failed to load topics:", err)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			if err := loader.Watch(ctx); err != nil {
+				logger.Error("Error watching topics", err)
+			}
+		}()
+	}
+
 	// Create a new root command with the manager and news service
 	rootCmd := cmd.NewRootCmd(manager, newsService)
 
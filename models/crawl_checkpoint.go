@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// CrawlCheckpoint is the progress of a crawl run, saved as the crawl goes so
+// that an interrupted run can be resumed where it left off.
+type CrawlCheckpoint struct {
+	RunID           string       `json:"run_id"`
+	Queue           []QueuedPage `json:"queue"`             // pages queued or being crawled
+	Visited         []string     `json:"visited"`           // pages crawled
+	Pending         []string     `json:"pending,omitempty"` // matched pages whose metadata was not fetched yet
+	TotalPages      int          `json:"total_pages"`
+	TotalLinks      int          `json:"total_links"`
+	MatchedLinks    int          `json:"matched_links"`
+	NotMatchedLinks int          `json:"not_matched_links"`
+	SavedAt         time.Time    `json:"saved_at"`
+}
+
+// QueuedPage is a page a crawl has still to crawl, at its depth from the
+// start URLs.
+type QueuedPage struct {
+	URL   string `json:"url"`
+	Depth int    `json:"depth"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Crawl run statuses
+const (
+	CrawlRunStatusRunning   = "running"
+	CrawlRunStatusCompleted = "completed"
+	CrawlRunStatusFailed    = "failed"
+	CrawlRunStatusCancelled = "cancelled"
+)
+
+// CrawlRun records a single execution of a crawl.
+type CrawlRun struct {
+	ID              string          `json:"id"`
+	SiteID          string          `json:"site_id"`
+	Options         json.RawMessage `json:"options,omitempty"`
+	Status          string          `json:"status"`
+	StartedAt       time.Time       `json:"started_at"`
+	EndedAt         *time.Time      `json:"ended_at,omitempty"` // nil while the run is running
+	TotalPages      int             `json:"total_pages"`
+	TotalLinks      int             `json:"total_links"`
+	MatchedLinks    int             `json:"matched_links"`
+	NotMatchedLinks int             `json:"not_matched_links"`
+	Error           string          `json:"error,omitempty"`
+}
+
+// Duration returns how long the run took, or how long it has been running.
+func (r *CrawlRun) Duration() time.Duration {
+	if r.EndedAt == nil {
+		return time.Since(r.StartedAt)
+	}
+	return r.EndedAt.Sub(r.StartedAt)
+}
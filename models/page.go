@@ -4,14 +4,67 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"time"
 )
 
-// PageData represents the data of a crawled page.
+// PageData represents the data of a crawled page. Relevance scores how
+// relevant the match is, from 0 to 1, and is what results are ranked by.
+// FirstSeen is when a crawl of the site first found the link, and RunID the
+// ID of that crawl run.
 type PageData struct {
-	URL             string   `json:"url,omitempty"`
-	MatchingTerms   []string `json:"matching_terms,omitempty"`
-	SimilarityScore float64  `json:"similarity_score,omitempty"`
-	Error           string   `json:"error,omitempty"`
+	URL           string   `json:"url,omitempty"`
+	MatchingTerms []string `json:"matching_terms,omitempty"`
+	Relevance     float64  `json:"relevance,omitempty"`
+	// Deprecated: SimilarityScore is no longer set. It is only read from
+	// results saved before Relevance, which are ranked by it.
+	SimilarityScore float64    `json:"similarity_score,omitempty"`
+	MatchLocations  []string   `json:"match_locations,omitempty"`
+	FirstSeen       *time.Time `json:"first_seen,omitempty"`
+	RunID           string     `json:"run_id,omitempty"`
+	PageMetadata
+	Error string `json:"error,omitempty"`
+}
+
+// Where in a page or the link to it the search terms were found.
+const (
+	MatchLocationURL    = "url"
+	MatchLocationAnchor = "anchor"
+	MatchLocationTitle  = "title"
+	MatchLocationBody   = "body"
+)
+
+// PageMetadata is what a page says about itself in its head, or what the
+// sitemap or feed that lists it says. ModifiedTime is the last modification
+// of the page a sitemap or feed gives.
+type PageMetadata struct {
+	Title         string     `json:"title,omitempty"`
+	Description   string     `json:"description,omitempty"`
+	Image         string     `json:"image,omitempty"`
+	CanonicalURL  string     `json:"canonical_url,omitempty"`
+	PublishedTime *time.Time `json:"published_time,omitempty"`
+	ModifiedTime  *time.Time `json:"modified_time,omitempty"`
+}
+
+// Merge overwrites the fields of m with the fields of other that are set.
+func (m *PageMetadata) Merge(other PageMetadata) {
+	if other.Title != "" {
+		m.Title = other.Title
+	}
+	if other.Description != "" {
+		m.Description = other.Description
+	}
+	if other.Image != "" {
+		m.Image = other.Image
+	}
+	if other.CanonicalURL != "" {
+		m.CanonicalURL = other.CanonicalURL
+	}
+	if other.PublishedTime != nil {
+		m.PublishedTime = other.PublishedTime
+	}
+	if other.ModifiedTime != nil {
+		m.ModifiedTime = other.ModifiedTime
+	}
 }
 
 // Validate checks if the PageData fields are valid.
@@ -45,9 +98,41 @@ func (p *PageData) UnmarshalBinary(data []byte) error {
 	return p.Validate()
 }
 
-// UpdatePageData updates the PageData with the matching terms, and similarity score.
-// It sets the MatchingTerms and SimilarityScore fields of the PageData.
-func (p *PageData) UpdatePageData(matchingTerms []string, similarityScore float64) {
-	p.MatchingTerms = matchingTerms
-	p.SimilarityScore = similarityScore
+// Merge merges other, a newer result for the same URL, into p. Matching terms
+// and match locations p does not have yet are added, the higher relevance and
+// similarity score are kept, the earlier FirstSeen is kept with its RunID,
+// and the metadata of other that is set replaces that of p.
+func (p *PageData) Merge(other PageData) {
+	p.MatchingTerms = union(p.MatchingTerms, other.MatchingTerms)
+	p.MatchLocations = union(p.MatchLocations, other.MatchLocations)
+
+	if other.Relevance > p.Relevance {
+		p.Relevance = other.Relevance
+	}
+	if other.SimilarityScore > p.SimilarityScore {
+		p.SimilarityScore = other.SimilarityScore
+	}
+	if other.FirstSeen != nil && (p.FirstSeen == nil || other.FirstSeen.Before(*p.FirstSeen)) {
+		p.FirstSeen = other.FirstSeen
+		p.RunID = other.RunID
+	}
+	p.PageMetadata.Merge(other.PageMetadata)
+	if other.Error != "" {
+		p.Error = other.Error
+	}
+}
+
+// union appends the values of b that are not in a to a.
+func union(a, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	for _, v := range a {
+		seen[v] = true
+	}
+	for _, v := range b {
+		if !seen[v] {
+			seen[v] = true
+			a = append(a, v)
+		}
+	}
+	return a
 }